@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+
+	"ush/exec"
+	"ush/rpc"
+)
+
+var agentFlags = struct {
+	exec.Spec
+	Socket string
+}{}
+
+func init() {
+	flag := agentCmd.Flags()
+	spec := &agentFlags.Spec
+
+	flag.DurationVarP(&spec.Timeout, "timeout", "t", time.Minute, "timeout of each command execution")
+	flag.IntVar(&spec.StderrBytes, "stderr_bytes", 4*1024, "number of bytes to read from command's stderr")
+	flag.IntVar(&spec.StdoutBytes, "stdout_bytes", 4*1024, "number of bytes to read from command's stdout")
+	flag.IntVarP(&spec.Parallel, "parallel", "p", 1, "number of parallel commands to execute")
+	flag.StringVar(&agentFlags.Socket, "socket", "", "unix socket to listen on, forwarded back to the controller over ssh")
+
+	rootCmd.AddCommand(agentCmd)
+}
+
+// agentCmd represents the agent subcommand. It is not meant to be run by
+// hand: ush exec -j starts it on each jump host over ssh and drives it
+// over the ush.v1.Executor gRPC protocol, unless --jump-protocol=legacy
+// falls back to the stdin/stdout transport.
+var agentCmd = &cobra.Command{
+	Use:    "agent [flags] <command>",
+	Short:  "Serve the ush.v1.Executor gRPC protocol for a jump host",
+	Hidden: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 || agentFlags.Socket == "" {
+			cmd.Help()
+			os.Exit(1)
+		}
+
+		s := &agentFlags.Spec
+		s.Command = args[0]
+		s.Args = args[1:]
+
+		if err := os.RemoveAll(agentFlags.Socket); err != nil {
+			fatalErr(err)
+		}
+
+		l, err := net.Listen("unix", agentFlags.Socket)
+		if err != nil {
+			fatalErr(err)
+		}
+		defer l.Close()
+
+		grpcServer := grpc.NewServer()
+		rpc.RegisterExecutor(grpcServer, s, exec.LocalRunner{})
+
+		fmt.Fprintln(os.Stderr, "ush agent listening on", agentFlags.Socket)
+		if err := grpcServer.Serve(l); err != nil {
+			fatalErr(err)
+		}
+	},
+}
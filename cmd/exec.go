@@ -9,6 +9,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"ush/exec"
+	"ush/rpc"
 	"ush/server"
 	"ush/strutil"
 )
@@ -17,6 +18,17 @@ var execFlags = struct {
 	exec.JumpSpec
 	ExcludeFile   string
 	JumpHostsFile string
+	JumpProtocol  string
+	SSHUser       string
+	SSHKeyFile    string
+	SSHKnownHosts string
+	SSHInsecure   bool
+	AuditLog      string
+	SessionDir    string
+	Push          []string
+	PushDirs      []string
+	BrokerAddr    string
+	BrokerBatch   int
 }{}
 
 func fatalErr(err error) {
@@ -35,9 +47,20 @@ func init() {
 	flag.StringVar(&spec.JumpCommand, "jump_cmd", exec.DefaultJumpCommand, "jump command where {.J} is replaced with a jump host")
 	flag.StringVarP(&execFlags.ExcludeFile, "exclude", "e", "", "file containing target and jump host exclusion list, one per line")
 	flag.StringVarP(&execFlags.JumpHostsFile, "jump_hosts", "j", "", "file containing jump hosts, one per line")
+	flag.StringVar(&execFlags.JumpProtocol, "jump-protocol", "grpc", "protocol to drive jump hosts with: grpc or legacy (stdin/stdout piping)")
 	flag.StringVarP(&spec.JumpHostsKeyFile, "jump_key", "k", "", "file containing ssh key to add to ssh-agent")
 	flag.StringVarP(&spec.FileOrigin, "file", "f", "", "path to a local file or a remote address `host:port`. Avoid serving large files as whole content is stored in memory and cached")
 	flag.StringVarP(&spec.ServeAddress, "address", "l", "localhost:5050", "local address for serving file")
+	flag.StringVar(&execFlags.SSHUser, "ssh-user", "", "ssh user, enables the native ssh transport instead of forking the ssh/ssh-agent binaries")
+	flag.StringVar(&execFlags.SSHKeyFile, "ssh-key", "", "path to the ssh private key to authenticate with")
+	flag.StringVar(&execFlags.SSHKnownHosts, "ssh-known-hosts", "", "path to a known_hosts file to verify host keys against")
+	flag.BoolVar(&execFlags.SSHInsecure, "ssh-insecure", false, "skip host key verification, ignoring --ssh-known-hosts")
+	flag.StringVar(&execFlags.AuditLog, "audit-log", "", "path to append newline-delimited JSON audit events to")
+	flag.StringVar(&execFlags.SessionDir, "session-dir", "", "directory to record full, uncapped target output to, replayable with `ush replay`")
+	flag.StringArrayVar(&execFlags.Push, "push", nil, "local:remote file to upload over SFTP to each target before running <command>, requires --ssh-user. Repeatable")
+	flag.StringArrayVar(&execFlags.PushDirs, "push-dir", nil, "localdir:remotedir directory to upload over SFTP to each target before running <command>, requires --ssh-user. Repeatable")
+	flag.StringVar(&execFlags.BrokerAddr, "broker-addr", "", "address of a `ush server` running in broker mode; read targets from it with NEXT/ACK/NACK instead of stdin")
+	flag.IntVar(&execFlags.BrokerBatch, "broker-batch", exec.DefaultBrokerBatch, "number of targets to check out of --broker-addr at a time")
 
 	rootCmd.AddCommand(execCmd)
 }
@@ -84,6 +107,60 @@ ush on the jump hosts is adjusted to the absolute value divided by the number of
 jump hosts. The rationale is that if you run ush exec -p 10 and you have 2 jump
 hosts, each jump host would do 5 parallel executions. If you have more jump
 hosts than the value of -p, the value -p on the jump hosts is set to 1.
+
+Passing --ssh-user switches to a native ssh transport instead of shelling out
+to the ssh and ssh-agent binaries: ush dials each target directly and runs
+<command> there. Combined with -j, targets are tunneled through a pool of
+multiplexed connections to the jump hosts instead of forking one ssh process
+(and one ssh-agent) per jump host.
+
+Example using the native ssh transport with jump hosts:
+
+	cat hosts.txt | ush exec -j jump_hosts.txt --ssh-user root --ssh-key jump.key -- hostid
+
+By default, -j drives each jump host over the ush.v1.Executor gRPC protocol:
+ush starts a "ush agent" on the jump host and forwards its Unix socket back
+over the ssh connection, then streams targets to it and reads results back
+with real flow control, per-target acks, and cancellation. Pass
+--jump-protocol=legacy to go back to piping targets through the jump host's
+stdin and parsing results from its stdout. The gRPC protocol runs the jump
+command with your own SSH_AUTH_SOCK: it spawns no ssh-agent per jump host
+and does not use -k, both of which only apply with --jump-protocol=legacy.
+
+Combined with -j, --audit-log and --session-dir still produce a per-target
+audit trail: with the default gRPC protocol, the controller proxies each
+target's lifecycle events and output back over the RunEvent stream and
+records them itself; with --jump-protocol=legacy, the flags are forwarded
+to the "ush exec" spawned on each jump host, which records them there.
+With the gRPC protocol the recorded output is the one the agent sends
+back, capped at --stdout_bytes/--stderr_bytes, not the full output.
+
+Combined with --ssh-user, --push and --push-dir upload a local file or
+directory to each target over SFTP before <command> runs there. Use {.F}
+in <command> to refer to the uploaded path, in addition to {.T} for the
+target itself.
+
+Example pushing a script and running it:
+
+	cat hosts.txt | ush exec --ssh-user root --ssh-key jump.key --push ./setup.sh:/tmp/setup.sh -- sh {.F}
+
+Pass --broker-addr instead of piping targets through stdin to pull them from
+a "ush exec --file hosts.txt --address host:port" running in broker mode:
+ush checks out --broker-batch targets at a time with NEXT, and only acks a
+target once <command> has actually finished running against it, nacking it
+back to the pool right away on failure. A target that's checked out but
+never acked or nacked (ush crashes mid-run) is reclaimed by the broker's
+lease timeout and handed to another worker, so a crash only loses its own
+in-flight batch instead of the whole queue. Not yet supported together with
+-j/--jump_hosts.
+
+The serving "ush exec" exits, and stops serving, once its own stdin ends,
+so keep its stdin open for as long as the broker is needed.
+
+Example driving a broker:
+
+	sleep infinity | ush exec --file hosts.txt --address localhost:5050 -- true &
+	ush exec --broker-addr localhost:5050 -- ssh user@{.T} -- hostid
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) == 0 {
@@ -95,6 +172,25 @@ hosts than the value of -p, the value -p on the jump hosts is set to 1.
 		s.Command = args[0]
 		s.Args = args[1:]
 
+		switch {
+		case execFlags.SessionDir != "":
+			auditor, err := exec.NewSessionAuditor(execFlags.SessionDir)
+			if err != nil {
+				fatalErr(err)
+			}
+			defer auditor.Close()
+			s.Auditor = auditor
+			s.SessionDir = execFlags.SessionDir
+		case execFlags.AuditLog != "":
+			auditor, err := exec.NewFileAuditor(execFlags.AuditLog)
+			if err != nil {
+				fatalErr(err)
+			}
+			defer auditor.Close()
+			s.Auditor = auditor
+			s.AuditLog = execFlags.AuditLog
+		}
+
 		var err error
 		var exclude strutil.StringSet
 		if execFlags.ExcludeFile != "" {
@@ -131,26 +227,95 @@ hosts than the value of -p, the value -p on the jump hosts is set to 1.
 			}()
 		}
 
-		targets := exec.Read(ctx, os.Stdin, exclude)
+		var targets <-chan string
+		var brokerDone func(target string, err error)
+		if execFlags.BrokerAddr != "" {
+			if execFlags.JumpHostsFile != "" {
+				fatalErr(fmt.Errorf("--broker-addr cannot be combined with -j/--jump_hosts yet"))
+			}
+			targets, brokerDone, err = exec.ReadFromBroker(ctx, execFlags.BrokerAddr, execFlags.BrokerBatch)
+			if err != nil {
+				fatalErr(err)
+			}
+		} else {
+			targets = exec.Read(ctx, os.Stdin, exclude)
+		}
 
-		if execFlags.JumpHostsFile == "" {
-			err := exec.Exec(ctx, os.Stdout, &s.Spec, targets)
+		var jumpHosts []string
+		if execFlags.JumpHostsFile != "" {
+			hosts, err := strutil.NewStringSetFromFile(execFlags.JumpHostsFile)
 			if err != nil {
 				fatalErr(err)
 			}
-			return
+
+			hosts.Remove(exclude.SortedStrings()...)
+
+			jumpHosts = hosts.SortedStrings()
 		}
 
-		hosts, err := strutil.NewStringSetFromFile(execFlags.JumpHostsFile)
-		if err != nil {
-			fatalErr(err)
+		if execFlags.SSHUser != "" {
+			var push, pushDirs []exec.PushFile
+			for _, p := range execFlags.Push {
+				pf, err := exec.ParsePushFile(p)
+				if err != nil {
+					fatalErr(fmt.Errorf("--push %q: %v", p, err))
+				}
+				push = append(push, pf)
+			}
+			for _, p := range execFlags.PushDirs {
+				pf, err := exec.ParsePushFile(p)
+				if err != nil {
+					fatalErr(fmt.Errorf("--push-dir %q: %v", p, err))
+				}
+				pushDirs = append(pushDirs, pf)
+			}
+
+			sshRunner, err := exec.NewSSHRunner(exec.SSHSpec{
+				User:           execFlags.SSHUser,
+				KeyFile:        execFlags.SSHKeyFile,
+				KnownHostsFile: execFlags.SSHKnownHosts,
+				Insecure:       execFlags.SSHInsecure,
+				JumpHosts:      jumpHosts,
+				Push:           push,
+				PushDirs:       pushDirs,
+			})
+			if err != nil {
+				fatalErr(err)
+			}
+			defer sshRunner.Close()
+
+			var runner exec.Runner = sshRunner
+			if brokerDone != nil {
+				runner = exec.BrokerRunner{Runner: runner, Done: brokerDone}
+			}
+
+			err = exec.ExecWith(ctx, os.Stdout, &s.Spec, targets, runner)
+			if err != nil {
+				fatalErr(err)
+			}
+			return
 		}
 
-		hosts.Remove(exclude.SortedStrings()...)
+		if execFlags.JumpHostsFile == "" {
+			var runner exec.Runner = exec.LocalRunner{}
+			if brokerDone != nil {
+				runner = exec.BrokerRunner{Runner: runner, Done: brokerDone}
+			}
 
-		s.JumpHosts = hosts.SortedStrings()
+			err := exec.ExecWith(ctx, os.Stdout, &s.Spec, targets, runner)
+			if err != nil {
+				fatalErr(err)
+			}
+			return
+		}
 
-		err = exec.JumpExec(ctx, os.Stdout, s, targets)
+		s.JumpHosts = jumpHosts
+
+		if execFlags.JumpProtocol == "legacy" {
+			err = exec.JumpExec(ctx, os.Stdout, s, targets)
+		} else {
+			err = rpc.JumpExec(ctx, os.Stdout, s, targets)
+		}
 		if err != nil {
 			fatalErr(err)
 		}
@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -13,6 +16,9 @@ import (
 
 var freqFlags = struct {
 	EncodeJSON bool
+	Follow     bool
+	Interval   time.Duration
+	Quantiles  string
 }{}
 
 func init() {
@@ -28,8 +34,29 @@ func init() {
 	for _, cmd := range subcmds {
 		flag := cmd.Flags()
 		flag.BoolVar(&freqFlags.EncodeJSON, "json", false, "encode output as json")
+		flag.BoolVar(&freqFlags.Follow, "follow", false, "keep reading stdin and redraw the histogram as results arrive, instead of waiting for EOF")
+		flag.DurationVar(&freqFlags.Interval, "interval", time.Second, "how often to redraw the histogram when --follow is set")
 		freqCmd.AddCommand(cmd)
 	}
+
+	freqDurationCmd.Flags().StringVar(&freqFlags.Quantiles, "quantiles", "", "comma separated quantiles, e.g. 0.5,0.95,0.99, to append as a percentile summary footer")
+
+	freqCmd.AddCommand(freqQuantileCmd)
+}
+
+// parseQuantiles parses a comma separated list of quantiles, e.g.
+// "0.5,0.95,0.99", as used by --quantiles and ush freq quantile.
+func parseQuantiles(s string) ([]float64, error) {
+	parts := strings.Split(s, ",")
+	qs := make([]float64, 0, len(parts))
+	for _, p := range parts {
+		q, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantile %q: %v", p, err)
+		}
+		qs = append(qs, q)
+	}
+	return qs, nil
 }
 
 // freqCmd represents the freq subcommand.
@@ -45,6 +72,20 @@ Examples:
 	echo -ne 'foo\nbar\n' | ush exec -- echo {.T} | ush freq stdout
 
 	for x in {1..3}; do echo $x; done | ush exec -p 3 -- sleep {.T} | ush freq duration 1s
+
+Pass --follow to redraw the histogram as results arrive instead of waiting
+for ush exec to finish, e.g. when piping a long-running batch straight
+into freq. --interval controls how often it redraws (default 1s):
+
+	ush exec -- sleep {.T} < hosts.txt | ush freq exitstatus --follow --interval 500ms
+
+Use ush freq quantile to print p50/p95/p99-style duration percentiles
+instead of a histogram, or pass --quantiles to ush freq duration to
+append them as a summary footer to its histogram:
+
+	cat hosts.txt | ush exec -- ping -c1 {.T} | ush freq quantile 0.5,0.95,0.99
+
+	cat hosts.txt | ush exec -- ping -c1 {.T} | ush freq duration 10ms --quantiles 0.5,0.99
 `,
 }
 
@@ -65,11 +106,33 @@ func encodeItems(items []freq.Item, err error) {
 	}
 }
 
+// streamItems runs freq.Stream against stdin with key until EOF,
+// redrawing the histogram every --interval instead of waiting for EOF
+// like encodeItems does.
+func streamItems(key freq.KeyFunc) {
+	emit := freq.EncodeWideStream(os.Stdout)
+	if freqFlags.EncodeJSON {
+		emit = func(items []freq.Item) {
+			if err := freq.EncodeJSON(os.Stdout, items); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}
+	}
+
+	if err := freq.Stream(context.Background(), os.Stdin, key, emit, freqFlags.Interval); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
 var freqStdoutCmd = &cobra.Command{
 	Use:   "stdout [flags] < results.json",
 	Short: "Print frequency of similar stdout from ush exec JSON output",
 	Run: func(cmd *cobra.Command, args []string) {
 		flag.Parse()
+		if freqFlags.Follow {
+			streamItems(freq.StdoutKey)
+			return
+		}
 		encodeItems(freq.Stdout(os.Stdin))
 	},
 }
@@ -79,6 +142,10 @@ var freqStderrCmd = &cobra.Command{
 	Short: "Print frequency of similar stderr from ush exec JSON output",
 	Run: func(cmd *cobra.Command, args []string) {
 		flag.Parse()
+		if freqFlags.Follow {
+			streamItems(freq.StderrKey)
+			return
+		}
 		encodeItems(freq.Stderr(os.Stdin))
 	},
 }
@@ -88,6 +155,10 @@ var freqExitStatusCmd = &cobra.Command{
 	Short: "Print frequency of similar exit status from ush exec JSON output",
 	Run: func(cmd *cobra.Command, args []string) {
 		flag.Parse()
+		if freqFlags.Follow {
+			streamItems(freq.ExitStatusKey)
+			return
+		}
 		encodeItems(freq.ExitStatus(os.Stdin))
 	},
 }
@@ -109,6 +180,57 @@ var freqDurationCmd = &cobra.Command{
 			return
 		}
 
-		encodeItems(freq.Duration(os.Stdin, d))
+		if freqFlags.Follow {
+			streamItems(freq.DurationKey(d))
+			return
+		}
+
+		if freqFlags.Quantiles == "" {
+			encodeItems(freq.Duration(os.Stdin, d))
+			return
+		}
+
+		qs, err := parseQuantiles(freqFlags.Quantiles)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+
+		items, quantiles, err := freq.DurationWithQuantiles(os.Stdin, d, qs)
+		encodeItems(items, err)
+		if err == nil {
+			if err := freq.EncodeQuantiles(os.Stdout, qs, quantiles); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}
+	},
+}
+
+var freqQuantileCmd = &cobra.Command{
+	Use:   "quantile [flags] 0.5,0.95,0.99 < results.json",
+	Short: "Print duration quantiles (e.g. p50, p95, p99) from ush exec JSON output",
+	Run: func(cmd *cobra.Command, args []string) {
+		flag.Parse()
+
+		if len(args) == 0 {
+			cmd.Help()
+			os.Exit(1)
+		}
+
+		qs, err := parseQuantiles(args[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+
+		quantiles, err := freq.Quantiles(os.Stdin, qs)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+
+		if err := freq.EncodeQuantiles(os.Stdout, qs, quantiles); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
 	},
 }
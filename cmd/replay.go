@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"ush/exec"
+)
+
+func init() {
+	rootCmd.AddCommand(replayCmd)
+}
+
+// replayCmd represents the replay subcommand.
+var replayCmd = &cobra.Command{
+	Use:   "replay <session-dir>",
+	Short: "Reprint a session recorded with ush exec --session-dir",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			cmd.Help()
+			os.Exit(1)
+		}
+
+		if err := exec.Replay(os.Stdout, args[0]); err != nil {
+			fatalErr(err)
+		}
+	},
+}
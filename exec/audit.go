@@ -0,0 +1,189 @@
+package exec
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventType identifies the kind of lifecycle event emitted to an Auditor.
+type EventType string
+
+// Event types emitted by Exec and JumpExec.
+const (
+	EventTargetStarted   EventType = "target_started"
+	EventTargetFinished  EventType = "target_finished"
+	EventJumpDialed      EventType = "jump_dialed"
+	EventJumpFailed      EventType = "jump_failed"
+	EventStdoutTruncated EventType = "stdout_truncated"
+	EventStderrTruncated EventType = "stderr_truncated"
+)
+
+// Event is a single lifecycle event of a command execution.
+type Event struct {
+	Type       EventType `json:"type"`
+	Time       time.Time `json:"time"`
+	Target     string    `json:"target,omitempty"`
+	JumpHost   string    `json:"jump_host,omitempty"`
+	PID        int       `json:"pid,omitempty"`
+	ExitStatus int       `json:"exit_status,omitempty"`
+	Duration   string    `json:"duration,omitempty"`
+	Err        string    `json:"error,omitempty"`
+}
+
+// Auditor receives the lifecycle events of every command Exec and JumpExec
+// run, and optionally records the full, uncapped output of a target.
+type Auditor interface {
+	// Emit records a single lifecycle event.
+	Emit(event Event) error
+	// RecordSession returns a writer that persists the full output of
+	// target. Callers close the writer once the target is done.
+	RecordSession(target string) (io.WriteCloser, error)
+}
+
+// nopAuditor discards every event and never records sessions. It is the
+// Auditor used by Exec and JumpExec when none is configured.
+type nopAuditor struct{}
+
+func (nopAuditor) Emit(Event) error                             { return nil }
+func (nopAuditor) RecordSession(string) (io.WriteCloser, error) { return nopWriteCloser{}, nil }
+
+type nopWriteCloser struct{}
+
+func (nopWriteCloser) Write(p []byte) (int, error) { return len(p), nil }
+func (nopWriteCloser) Close() error                { return nil }
+
+// AuditorOrNop returns a, or a no-op Auditor if a is nil. Exported so
+// callers outside this package (e.g. rpc.JumpExec, which proxies jump
+// host events into a controller-configured Auditor) don't have to nil-check
+// before every call.
+func AuditorOrNop(a Auditor) Auditor {
+	if a == nil {
+		return nopAuditor{}
+	}
+	return a
+}
+
+// FileAuditor writes newline-delimited JSON events to a file. It does not
+// record sessions.
+type FileAuditor struct {
+	mu  sync.Mutex
+	f   *os.File
+	enc *json.Encoder
+}
+
+// NewFileAuditor opens (or creates) path and returns a FileAuditor that
+// appends events to it.
+func NewFileAuditor(path string) (*FileAuditor, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileAuditor{f: f, enc: json.NewEncoder(f)}, nil
+}
+
+// Emit implements Auditor.
+func (a *FileAuditor) Emit(event Event) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.enc.Encode(event)
+}
+
+// RecordSession implements Auditor. FileAuditor does not record sessions.
+func (a *FileAuditor) RecordSession(string) (io.WriteCloser, error) {
+	return nopWriteCloser{}, nil
+}
+
+// Close closes the underlying audit log file.
+func (a *FileAuditor) Close() error {
+	return a.f.Close()
+}
+
+// SessionAuditor writes newline-delimited JSON events to "events.json"
+// inside dir, and records the full, uncapped stdout/stderr of every
+// target into per-target files under dir, so a `ush replay` can reprint
+// what ran.
+type SessionAuditor struct {
+	dir string
+
+	mu  sync.Mutex
+	f   *os.File
+	enc *json.Encoder
+}
+
+// NewSessionAuditor creates dir if needed and returns a SessionAuditor
+// that records sessions and events into it.
+func NewSessionAuditor(dir string) (*SessionAuditor, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, "events.json"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SessionAuditor{dir: dir, f: f, enc: json.NewEncoder(f)}, nil
+}
+
+// Emit implements Auditor.
+func (a *SessionAuditor) Emit(event Event) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.enc.Encode(event)
+}
+
+// RecordSession implements Auditor. It opens (or creates) a per-target
+// file under the session directory and returns it for the caller to
+// write the target's full, uncapped output to.
+func (a *SessionAuditor) RecordSession(target string) (io.WriteCloser, error) {
+	name := strings.Replace(target, string(filepath.Separator), "_", -1)
+	return os.OpenFile(filepath.Join(a.dir, name), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+}
+
+// Close closes the underlying events file.
+func (a *SessionAuditor) Close() error {
+	return a.f.Close()
+}
+
+// Replay reprints a session recorded by SessionAuditor to w: every event
+// from events.json, and for target_finished events, the full contents of
+// the target's recorded output file, if any.
+func Replay(w io.Writer, dir string) error {
+	f, err := os.Open(filepath.Join(dir, "events.json"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	for dec.More() {
+		var event Event
+		if err := dec.Decode(&event); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(w, "%s %s target=%q jump_host=%q pid=%d exit_status=%d duration=%s error=%q\n",
+			event.Time.Format(time.RFC3339), event.Type, event.Target, event.JumpHost,
+			event.PID, event.ExitStatus, event.Duration, event.Err)
+
+		if event.Type != EventTargetFinished || event.Target == "" {
+			continue
+		}
+
+		name := strings.Replace(event.Target, string(filepath.Separator), "_", -1)
+		session, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		io.Copy(w, session)
+		session.Close()
+	}
+
+	return nil
+}
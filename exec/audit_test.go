@@ -0,0 +1,110 @@
+package exec
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileAuditor(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ush-audit-test")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "events.json")
+	a, err := NewFileAuditor(path)
+	if err != nil {
+		t.Fatalf("NewFileAuditor: %v", err)
+	}
+
+	if err := a.Emit(Event{Type: EventTargetStarted, Target: "t"}); err != nil {
+		t.Fatalf("Emit: %v", err)
+	}
+	if err := a.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if w, err := a.RecordSession("t"); err != nil || w == nil {
+		t.Fatalf("RecordSession: %v, %v", w, err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	var event Event
+	if err := json.Unmarshal(bytes.TrimSpace(data), &event); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if event.Type != EventTargetStarted || event.Target != "t" {
+		t.Fatalf("unexpected event: %+v", event)
+	}
+}
+
+func TestSessionAuditorAndReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ush-session-test")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	a, err := NewSessionAuditor(dir)
+	if err != nil {
+		t.Fatalf("NewSessionAuditor: %v", err)
+	}
+
+	session, err := a.RecordSession("host/a")
+	if err != nil {
+		t.Fatalf("RecordSession: %v", err)
+	}
+	session.Write([]byte("hello output"))
+	session.Close()
+
+	start := time.Now()
+	if err := a.Emit(Event{Type: EventTargetStarted, Time: start, Target: "host/a"}); err != nil {
+		t.Fatalf("Emit: %v", err)
+	}
+	if err := a.Emit(Event{Type: EventTargetFinished, Time: start, Target: "host/a", ExitStatus: 0}); err != nil {
+		t.Fatalf("Emit: %v", err)
+	}
+	if err := a.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	var b bytes.Buffer
+	if err := Replay(&b, dir); err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+
+	out := b.String()
+	if !bytes.Contains([]byte(out), []byte("target_started")) {
+		t.Fatalf("Replay output missing target_started: %q", out)
+	}
+	if !bytes.Contains([]byte(out), []byte("hello output")) {
+		t.Fatalf("Replay output missing recorded session content: %q", out)
+	}
+}
+
+func TestNopAuditor(t *testing.T) {
+	a := AuditorOrNop(nil)
+	if err := a.Emit(Event{}); err != nil {
+		t.Fatalf("Emit: %v", err)
+	}
+	w, err := a.RecordSession("t")
+	if err != nil {
+		t.Fatalf("RecordSession: %v", err)
+	}
+	if n, err := w.Write([]byte("ignored")); n != len("ignored") || err != nil {
+		t.Fatalf("Write: n=%d, err=%v", n, err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
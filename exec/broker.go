@@ -0,0 +1,105 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"ush/server"
+)
+
+// DefaultBrokerBatch is the number of targets ReadFromBroker checks out
+// of the broker server at a time.
+const DefaultBrokerBatch = 100
+
+// ReadFromBroker connects to a server.Server listening in broker mode at
+// addr, and emits targets to the returned channel as they are checked
+// out with NEXT, batch targets at a time. The returned channel is
+// unbuffered, closed when the broker runs dry, ctx is cancelled, or a
+// protocol error occurs.
+//
+// A target is only removed from the broker's pool once the returned
+// done func is called for it with the error (if any) its execution
+// produced - not when it is merely handed off on the channel. Callers
+// must call done exactly once for every target they receive, typically
+// via BrokerRunner. A target whose done is never called (e.g. the
+// process crashes first) is left for the broker's lease timeout to
+// reclaim and hand out again, instead of being silently dropped.
+func ReadFromBroker(ctx context.Context, addr string, batch int) (<-chan string, func(target string, err error), error) {
+	client, err := server.Dial(addr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	targets := make(chan string)
+
+	var mu sync.Mutex // serializes client access across the dequeue loop and done
+	var wg sync.WaitGroup
+
+	go func() {
+		defer func() {
+			close(targets)
+			wg.Wait()
+			mu.Lock()
+			client.Close()
+			mu.Unlock()
+		}()
+
+		for {
+			mu.Lock()
+			got, err := client.Next(batch)
+			mu.Unlock()
+			if err != nil || len(got) == 0 {
+				return
+			}
+
+			for i, t := range got {
+				wg.Add(1) // before the send, so a receiver's done can never run ahead of it
+				select {
+				case targets <- t:
+				case <-ctx.Done():
+					wg.Done()
+					mu.Lock()
+					client.Nack(got[i:]...)
+					mu.Unlock()
+					return
+				}
+			}
+		}
+	}()
+
+	done := func(target string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		defer wg.Done()
+		if err != nil {
+			client.Nack(target)
+		} else {
+			client.Ack(target)
+		}
+	}
+
+	return targets, done, nil
+}
+
+// BrokerRunner wraps Runner so a target checked out via ReadFromBroker is
+// only acked once it has actually run, and nacked back to the pool
+// immediately if running it failed - never merely on being handed off
+// the targets channel.
+type BrokerRunner struct {
+	Runner Runner
+	Done   func(target string, err error)
+}
+
+// Run implements Runner.
+func (r BrokerRunner) Run(ctx context.Context, s *Spec, target string) Result {
+	res := r.Runner.Run(ctx, s, target)
+
+	var err error
+	if res.Err != "" {
+		err = errors.New(res.Err)
+	}
+	r.Done(target, err)
+
+	return res
+}
@@ -0,0 +1,154 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"ush/server"
+)
+
+func startBrokerTestServer(t *testing.T, targets string) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "ush-broker-test")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	f := filepath.Join(dir, "targets")
+	if err := ioutil.WriteFile(f, []byte(targets), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("net.Listen: %v", err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	s, err := server.NewServer(f, addr)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	if _, err := s.Serve(ctx); err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+
+	return addr
+}
+
+func TestReadFromBroker(t *testing.T) {
+	addr := startBrokerTestServer(t, "a\nb\nc\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	targets, done, err := ReadFromBroker(ctx, addr, 2)
+	if err != nil {
+		t.Fatalf("ReadFromBroker: %v", err)
+	}
+
+	var got []string
+	for target := range targets {
+		got = append(got, target)
+		done(target, nil)
+	}
+
+	sort.Strings(got)
+	want := []string{"a", "b", "c"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+// TestReadFromBrokerAcksOnlyOnDone makes sure a target is removed from the
+// broker's pool only once done reports it finished, not as soon as it is
+// handed off on the targets channel - otherwise a crash between checkout
+// and completion would silently drop it instead of leaving it for another
+// worker to retry.
+func TestReadFromBrokerAcksOnlyOnDone(t *testing.T) {
+	addr := startBrokerTestServer(t, "a\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	targets, done, err := ReadFromBroker(ctx, addr, 1)
+	if err != nil {
+		t.Fatalf("ReadFromBroker: %v", err)
+	}
+
+	target, ok := <-targets
+	if !ok || target != "a" {
+		t.Fatalf("unexpected target: %q, ok=%v", target, ok)
+	}
+
+	inspect, err := server.Dial(addr)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer inspect.Close()
+
+	// "a" was handed off but done has not been called yet: it must still
+	// be checked out, not available for another worker to pull.
+	if n, err := inspect.Next(1); err != nil || len(n) != 0 {
+		t.Fatalf("target available before done was called: %v, err=%v", n, err)
+	}
+
+	// Simulate the run failing: nack puts it straight back in the pool.
+	done(target, errors.New("boom"))
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		n, err := inspect.Next(1)
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		if len(n) == 1 && n[0] == "a" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("target was not returned to the pool after a failed done")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+type fakeRunner struct{}
+
+func (fakeRunner) Run(ctx context.Context, s *Spec, target string) Result {
+	return Result{Target: target, Err: "command failed"}
+}
+
+func TestBrokerRunner(t *testing.T) {
+	var doneTarget string
+	var doneErr error
+	r := BrokerRunner{
+		Runner: fakeRunner{},
+		Done: func(target string, err error) {
+			doneTarget = target
+			doneErr = err
+		},
+	}
+
+	res := r.Run(context.Background(), &Spec{}, "t")
+	if res.Target != "t" || res.Err != "command failed" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if doneTarget != "t" || doneErr == nil || doneErr.Error() != "command failed" {
+		t.Fatalf("done was not called with the result's error: target=%q, err=%v", doneTarget, doneErr)
+	}
+}
@@ -34,6 +34,7 @@ type Spec struct {
 	StderrBytes  int
 	FileOrigin   string
 	ServeAddress string
+	Auditor      Auditor
 }
 
 // Validate checks the spec. Returns error if required settings are not set.
@@ -69,6 +70,13 @@ type Result struct {
 // Exec executes job s on targets until the targets channel is closed or
 // ctx is cancelled. Markup {.T} in s.Command is replaced with each input.
 func Exec(ctx context.Context, w io.Writer, s *Spec, input <-chan string) error {
+	return ExecWith(ctx, w, s, input, LocalRunner{})
+}
+
+// ExecWith is like Exec but runs every target through runner instead of
+// always forking a local process. Use it to plug in SSHRunner or any
+// other Runner implementation.
+func ExecWith(ctx context.Context, w io.Writer, s *Spec, input <-chan string, runner Runner) error {
 	if err := s.Validate(); err != nil {
 		return err
 	}
@@ -86,7 +94,7 @@ func Exec(ctx context.Context, w io.Writer, s *Spec, input <-chan string) error
 					if !more {
 						return nil
 					}
-					result := runCmd(ctx, s, t)
+					result := runner.Run(ctx, s, t)
 					encoder.Encode(result)
 				}
 			}
@@ -97,6 +105,8 @@ func Exec(ctx context.Context, w io.Writer, s *Spec, input <-chan string) error
 }
 
 func runCmd(ctx context.Context, s *Spec, target string) Result {
+	auditor := AuditorOrNop(s.Auditor)
+
 	res := Result{
 		Target:    target,
 		StartTime: time.Now(),
@@ -104,6 +114,15 @@ func runCmd(ctx context.Context, s *Spec, target string) Result {
 
 	var stdout, stderr bytes.Buffer
 
+	session, err := auditor.RecordSession(target)
+	if err != nil {
+		res.Err = err.Error()
+		res.EndTime = time.Now()
+		res.Duration = res.EndTime.Sub(res.StartTime).String()
+		return res
+	}
+	defer session.Close()
+
 	cmd := strings.Replace(s.Command, "{.T}", target, -1)
 	args := make([]string, 0, len(s.Args))
 	for _, arg := range s.Args {
@@ -111,8 +130,14 @@ func runCmd(ctx context.Context, s *Spec, target string) Result {
 	}
 
 	oscmd := exec.Command(cmd, args...)
-	oscmd.Stdout = &lossyWriter{Limit: s.StdoutBytes, Buffer: &stdout}
-	oscmd.Stderr = &lossyWriter{Limit: s.StderrBytes, Buffer: &stderr}
+	oscmd.Stdout = io.MultiWriter(&lossyWriter{
+		Limit: s.StdoutBytes, Buffer: &stdout,
+		OnTruncate: func() { auditor.Emit(Event{Type: EventStdoutTruncated, Time: time.Now(), Target: target}) },
+	}, session)
+	oscmd.Stderr = io.MultiWriter(&lossyWriter{
+		Limit: s.StderrBytes, Buffer: &stderr,
+		OnTruncate: func() { auditor.Emit(Event{Type: EventStderrTruncated, Time: time.Now(), Target: target}) },
+	}, session)
 	oscmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
 
 	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
@@ -127,7 +152,16 @@ func runCmd(ctx context.Context, s *Spec, target string) Result {
 		}
 	}()
 
-	if err := oscmd.Run(); err != nil {
+	if err := oscmd.Start(); err != nil {
+		res.Err = err.Error()
+		res.EndTime = time.Now()
+		res.Duration = res.EndTime.Sub(res.StartTime).String()
+		return res
+	}
+
+	auditor.Emit(Event{Type: EventTargetStarted, Time: res.StartTime, Target: target, PID: oscmd.Process.Pid})
+
+	if err := oscmd.Wait(); err != nil {
 		res.Err = err.Error()
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
@@ -142,6 +176,17 @@ func runCmd(ctx context.Context, s *Spec, target string) Result {
 	res.Duration = res.EndTime.Sub(res.StartTime).String()
 	res.Stdout = stdout.String()
 	res.Stderr = stderr.String()
+
+	auditor.Emit(Event{
+		Type:       EventTargetFinished,
+		Time:       res.EndTime,
+		Target:     target,
+		PID:        oscmd.Process.Pid,
+		ExitStatus: res.ExitStatus,
+		Duration:   res.Duration,
+		Err:        res.Err,
+	})
+
 	return res
 }
 
@@ -149,6 +194,8 @@ func runCmd(ctx context.Context, s *Spec, target string) Result {
 type lossyWriter struct {
 	Limit  int
 	Buffer *bytes.Buffer
+	// OnTruncate, if set, is called once when the limit is reached.
+	OnTruncate func()
 }
 
 // Write writes up to w.Limit bytes to w.Buffer.
@@ -170,6 +217,9 @@ func (w *lossyWriter) Write(p []byte) (int, error) {
 	}
 	if w.Buffer.Len() == w.Limit {
 		w.Buffer.WriteString("[...]")
+		if w.OnTruncate != nil {
+			w.OnTruncate()
+		}
 	}
 	return writeSize, nil
 }
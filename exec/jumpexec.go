@@ -13,6 +13,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 )
@@ -37,6 +38,15 @@ type JumpSpec struct {
 	JumpHostsKeyFile string
 	JumpCommand      string
 	JumpHosts        []string
+
+	// AuditLog or SessionDir, when set, is forwarded as --audit-log or
+	// --session-dir to the `ush exec` this legacy transport spawns on
+	// each jump host, so per-target events and sessions are recorded
+	// there too; when both are set, only SessionDir is forwarded. The gRPC transport (rpc.JumpExec) doesn't need these:
+	// it proxies target lifecycle events back over the RunEvent stream
+	// into Auditor instead.
+	AuditLog   string
+	SessionDir string
 }
 
 // Validate checks the spec. Returns error if required settings are not set.
@@ -65,6 +75,8 @@ func JumpExec(ctx context.Context, w io.Writer, s *JumpSpec, targets <-chan stri
 		parallel = 1 // TODO: warn about too many jump hosts? cut some?
 	}
 
+	auditor := AuditorOrNop(s.Auditor)
+
 	var mu sync.Mutex
 	g, ctx := errgroup.WithContext(ctx)
 
@@ -72,16 +84,20 @@ func JumpExec(ctx context.Context, w io.Writer, s *JumpSpec, targets <-chan stri
 		// ssh-agent is a bottleneck; spawn one per jump host
 		agentcmd, authsock, err := sshAgent(ctx, host)
 		if err != nil {
+			auditor.Emit(Event{Type: EventJumpFailed, Time: time.Now(), JumpHost: host, Err: err.Error()})
 			return err
 		}
 
 		if s.JumpHostsKeyFile != "" {
 			err = sshAddKey(ctx, host, authsock, s.JumpHostsKeyFile)
 			if err != nil {
+				auditor.Emit(Event{Type: EventJumpFailed, Time: time.Now(), JumpHost: host, Err: err.Error()})
 				return fmt.Errorf("ssh-add failed: %v", err)
 			}
 		}
 
+		auditor.Emit(Event{Type: EventJumpDialed, Time: time.Now(), JumpHost: host})
+
 		// start ssh to jump host
 		pr, pw := io.Pipe()
 		stdout := &synchronizedWriter{Writer: w, Mutex: &mu}
@@ -99,9 +115,13 @@ func JumpExec(ctx context.Context, w io.Writer, s *JumpSpec, targets <-chan stri
 			"--parallel=" + strconv.Itoa(parallel),
 			"--stdout_bytes=" + strconv.Itoa(s.StdoutBytes),
 			"--stderr_bytes=" + strconv.Itoa(s.StderrBytes),
-			"--",
-			s.Command,
 		}
+		if s.SessionDir != "" {
+			ushargs = append(ushargs, "--session-dir="+s.SessionDir)
+		} else if s.AuditLog != "" {
+			ushargs = append(ushargs, "--audit-log="+s.AuditLog)
+		}
+		ushargs = append(ushargs, "--", s.Command)
 
 		args = append(args, ushargs...)
 		args = append(args, s.Args...)
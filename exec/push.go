@@ -0,0 +1,143 @@
+package exec
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+
+	"ush/server"
+)
+
+// ErrInvalidPush indicates a --push or --push-dir flag was not of the
+// form local:remote.
+var ErrInvalidPush = errors.New("push spec must be of the form local:remote")
+
+// PushFile is one local:remote pair to upload to a target, over SFTP,
+// before its command runs.
+type PushFile struct {
+	LocalPath  string
+	RemotePath string
+}
+
+// expand replaces {.T} with target and {.F} with remoteFile in s.
+func expand(s, target, remoteFile string) string {
+	s = strings.Replace(s, "{.T}", target, -1)
+	s = strings.Replace(s, "{.F}", remoteFile, -1)
+	return s
+}
+
+// ParsePushFile parses a --push or --push-dir flag value of the form
+// local:remote.
+func ParsePushFile(s string) (PushFile, error) {
+	parts := strings.SplitN(s, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return PushFile{}, ErrInvalidPush
+	}
+	return PushFile{LocalPath: parts[0], RemotePath: parts[1]}, nil
+}
+
+// pusher uploads files and directories to a target over SFTP before its
+// command runs. Each local file's content is cached in memory on first
+// use via server.Cache, so pushing a 500 MB tarball to thousands of
+// targets only reads it from disk once.
+type pusher struct {
+	files []PushFile
+	dirs  []PushFile
+
+	mu    sync.Mutex
+	cache map[string]*server.Cache
+}
+
+func newPusher(files, dirs []PushFile) *pusher {
+	return &pusher{files: files, dirs: dirs, cache: make(map[string]*server.Cache)}
+}
+
+func (p *pusher) cacheFor(path string) (*server.Cache, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if c, ok := p.cache[path]; ok {
+		return c, nil
+	}
+
+	c, err := server.NewCache(path)
+	if err != nil {
+		return nil, err
+	}
+
+	p.cache[path] = c
+	return c, nil
+}
+
+// push uploads every configured file and directory to target over
+// client, and returns the remote path {.F} expands to in the command
+// template: the first --push file's remote path, or the first
+// --push-dir's remote root when there is no --push file.
+func (p *pusher) push(client *ssh.Client, target string) (string, error) {
+	sc, err := sftp.NewClient(client)
+	if err != nil {
+		return "", fmt.Errorf("sftp to %s: %v", target, err)
+	}
+	defer sc.Close()
+
+	for _, f := range p.files {
+		if err := p.pushFile(sc, f.LocalPath, f.RemotePath); err != nil {
+			return "", err
+		}
+	}
+
+	for _, d := range p.dirs {
+		if err := p.pushDir(sc, d.LocalPath, d.RemotePath); err != nil {
+			return "", err
+		}
+	}
+
+	if len(p.files) > 0 {
+		return p.files[0].RemotePath, nil
+	}
+	return p.dirs[0].RemotePath, nil
+}
+
+func (p *pusher) pushFile(sc *sftp.Client, local, remote string) error {
+	cache, err := p.cacheFor(local)
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(remote); dir != "." {
+		sc.MkdirAll(dir)
+	}
+
+	f, err := sc.Create(remote)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(cache.Bytes())
+	return err
+}
+
+func (p *pusher) pushDir(sc *sftp.Client, localDir, remoteDir string) error {
+	return filepath.Walk(localDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(localDir, path)
+		if err != nil {
+			return err
+		}
+
+		return p.pushFile(sc, path, filepath.ToSlash(filepath.Join(remoteDir, rel)))
+	})
+}
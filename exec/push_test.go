@@ -0,0 +1,63 @@
+package exec
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParsePushFile(t *testing.T) {
+	cases := []struct {
+		In   string
+		Want PushFile
+		Err  error
+	}{
+		{In: "local.tar:/remote/local.tar", Want: PushFile{LocalPath: "local.tar", RemotePath: "/remote/local.tar"}},
+		{In: "a:b:c", Want: PushFile{LocalPath: "a", RemotePath: "b:c"}},
+		{In: "noremote:", Err: ErrInvalidPush},
+		{In: ":noLocal", Err: ErrInvalidPush},
+		{In: "nocolon", Err: ErrInvalidPush},
+	}
+
+	for i, tc := range cases {
+		got, err := ParsePushFile(tc.In)
+		if err != tc.Err {
+			t.Fatalf("case %d: err = %v, want %v", i, err, tc.Err)
+		}
+		if err == nil && got != tc.Want {
+			t.Fatalf("case %d: got %+v, want %+v", i, got, tc.Want)
+		}
+	}
+}
+
+func TestPusherCacheFor(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ush-push-test")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "payload")
+	if err := ioutil.WriteFile(path, []byte("hello"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	p := newPusher(nil, nil)
+
+	c1, err := p.cacheFor(path)
+	if err != nil {
+		t.Fatalf("cacheFor: %v", err)
+	}
+	if string(c1.Bytes()) != "hello" {
+		t.Fatalf("unexpected cached bytes: %q", c1.Bytes())
+	}
+
+	c2, err := p.cacheFor(path)
+	if err != nil {
+		t.Fatalf("cacheFor: %v", err)
+	}
+	if c1 != c2 {
+		t.Fatalf("cacheFor returned a different *server.Cache on second call, want the cached one")
+	}
+}
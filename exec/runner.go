@@ -0,0 +1,19 @@
+package exec
+
+import "context"
+
+// Runner executes a single command against target and returns its Result.
+// Exec calls Run once per target, in parallel, until the input channel is
+// closed or ctx is cancelled.
+type Runner interface {
+	Run(ctx context.Context, s *Spec, target string) Result
+}
+
+// LocalRunner runs commands as local child processes via os/exec. It is
+// the default Runner used by Exec.
+type LocalRunner struct{}
+
+// Run implements Runner.
+func (LocalRunner) Run(ctx context.Context, s *Spec, target string) Result {
+	return runCmd(ctx, s, target)
+}
@@ -0,0 +1,310 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SSHSpec errors.
+var (
+	ErrNoSSHUser    = errors.New("ssh user not set")
+	ErrNoKnownHosts = errors.New("known_hosts file not set, use --ssh-insecure to skip host key checks")
+)
+
+// SSHSpec configures the native SSH transport used by SSHRunner.
+type SSHSpec struct {
+	User           string
+	KeyFile        string
+	KnownHostsFile string
+	Insecure       bool
+	JumpHosts      []string
+	Push           []PushFile
+	PushDirs       []PushFile
+}
+
+// Validate checks the spec. Returns error if required settings are not set.
+func (s *SSHSpec) Validate() error {
+	switch {
+	case s.User == "":
+		return ErrNoSSHUser
+	case !s.Insecure && s.KnownHostsFile == "":
+		return ErrNoKnownHosts
+	default:
+		return nil
+	}
+}
+
+// SSHRunner is a Runner that connects to targets using
+// golang.org/x/crypto/ssh instead of forking the system ssh binary.
+//
+// When s.JumpHosts is set, connections to targets are tunneled through a
+// pool of multiplexed *ssh.Client connections to the jump hosts, so a
+// single TCP connection per jump host carries every session opened for
+// the targets behind it, instead of one ssh process (and one ssh-agent)
+// per jump host per command.
+type SSHRunner struct {
+	spec   SSHSpec
+	config *ssh.ClientConfig
+	pusher *pusher
+
+	mu    sync.Mutex
+	jumps map[string]*ssh.Client
+}
+
+// NewSSHRunner validates s and returns a SSHRunner ready to dial targets.
+func NewSSHRunner(s SSHSpec) (*SSHRunner, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	config, err := sshClientConfig(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var p *pusher
+	if len(s.Push) > 0 || len(s.PushDirs) > 0 {
+		p = newPusher(s.Push, s.PushDirs)
+	}
+
+	return &SSHRunner{
+		spec:   s,
+		config: config,
+		pusher: p,
+		jumps:  make(map[string]*ssh.Client),
+	}, nil
+}
+
+func sshClientConfig(s SSHSpec) (*ssh.ClientConfig, error) {
+	var auth []ssh.AuthMethod
+
+	if s.KeyFile != "" {
+		key, err := ioutil.ReadFile(s.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if conn, err := net.Dial("unix", sock); err == nil {
+			auth = append(auth, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+		}
+	}
+
+	hostKeyCallback := ssh.InsecureIgnoreHostKey()
+	if !s.Insecure {
+		cb, err := knownhosts.New(s.KnownHostsFile)
+		if err != nil {
+			return nil, err
+		}
+		hostKeyCallback = cb
+	}
+
+	return &ssh.ClientConfig{
+		User:            s.User,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         10 * time.Second,
+	}, nil
+}
+
+// Run implements Runner. It dials target over ssh, optionally tunneled
+// through a pooled jump host connection, runs s.Command with {.T}
+// replaced by target, and captures its output the same way LocalRunner
+// does, including recording it with s.Auditor when one is configured.
+func (r *SSHRunner) Run(ctx context.Context, s *Spec, target string) Result {
+	auditor := AuditorOrNop(s.Auditor)
+
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	res := Result{
+		Target:    target,
+		StartTime: time.Now(),
+	}
+
+	session, err := auditor.RecordSession(target)
+	if err != nil {
+		return sshFail(res, err)
+	}
+	defer session.Close()
+
+	client, err := r.dial(target)
+	if err != nil {
+		return sshFail(res, err)
+	}
+	defer client.Close() // not pooled: one client per target, even when tunneled through a pooled jump host connection
+
+	var remoteFile string
+	if r.pusher != nil {
+		remoteFile, err = r.pusher.push(client, target)
+		if err != nil {
+			return sshFail(res, err)
+		}
+	}
+
+	sshSession, err := client.NewSession()
+	if err != nil {
+		return sshFail(res, err)
+	}
+	defer sshSession.Close()
+
+	var stdout, stderr bytes.Buffer
+	sshSession.Stdout = io.MultiWriter(&lossyWriter{
+		Limit: s.StdoutBytes, Buffer: &stdout,
+		OnTruncate: func() { auditor.Emit(Event{Type: EventStdoutTruncated, Time: time.Now(), Target: target}) },
+	}, session)
+	sshSession.Stderr = io.MultiWriter(&lossyWriter{
+		Limit: s.StderrBytes, Buffer: &stderr,
+		OnTruncate: func() { auditor.Emit(Event{Type: EventStderrTruncated, Time: time.Now(), Target: target}) },
+	}, session)
+
+	cmd := expand(s.Command, target, remoteFile)
+	args := make([]string, 0, len(s.Args))
+	for _, arg := range s.Args {
+		args = append(args, expand(arg, target, remoteFile))
+	}
+
+	auditor.Emit(Event{Type: EventTargetStarted, Time: res.StartTime, Target: target})
+
+	done := make(chan error, 1)
+	go func() { done <- sshSession.Run(strings.Join(append([]string{cmd}, args...), " ")) }()
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		sshSession.Signal(ssh.SIGKILL)
+		err = ctx.Err()
+	}
+
+	if err != nil {
+		res.Err = err.Error()
+		if exitErr, ok := err.(*ssh.ExitError); ok {
+			res.ExitStatus = exitErr.ExitStatus()
+		}
+	}
+
+	res.EndTime = time.Now()
+	res.Duration = res.EndTime.Sub(res.StartTime).String()
+	res.Stdout = stdout.String()
+	res.Stderr = stderr.String()
+
+	auditor.Emit(Event{
+		Type:       EventTargetFinished,
+		Time:       res.EndTime,
+		Target:     target,
+		ExitStatus: res.ExitStatus,
+		Duration:   res.Duration,
+		Err:        res.Err,
+	})
+
+	return res
+}
+
+func sshFail(res Result, err error) Result {
+	res.Err = err.Error()
+	res.EndTime = time.Now()
+	res.Duration = res.EndTime.Sub(res.StartTime).String()
+	return res
+}
+
+// dial returns a *ssh.Client connected to target, tunneling through a
+// pooled jump host connection when r.spec.JumpHosts is set.
+func (r *SSHRunner) dial(target string) (*ssh.Client, error) {
+	addr := hostPort(target)
+
+	if len(r.spec.JumpHosts) == 0 {
+		return ssh.Dial("tcp", addr, r.config)
+	}
+
+	jump := r.spec.JumpHosts[hashHost(target)%len(r.spec.JumpHosts)]
+
+	jumpClient, err := r.jumpClient(jump)
+	if err != nil {
+		return nil, fmt.Errorf("jump host %s: %v", jump, err)
+	}
+
+	conn, err := jumpClient.Dial("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s via %s: %v", target, jump, err)
+	}
+
+	ncc, chans, reqs, err := ssh.NewClientConn(conn, addr, r.config)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return ssh.NewClient(ncc, chans, reqs), nil
+}
+
+// jumpClient returns the pooled *ssh.Client for jump, dialing and caching
+// it on first use.
+func (r *SSHRunner) jumpClient(jump string) (*ssh.Client, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if c, ok := r.jumps[jump]; ok {
+		return c, nil
+	}
+
+	c, err := ssh.Dial("tcp", hostPort(jump), r.config)
+	if err != nil {
+		return nil, err
+	}
+
+	r.jumps[jump] = c
+	return c, nil
+}
+
+// Close closes every pooled jump host connection.
+func (r *SSHRunner) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var err error
+	for _, c := range r.jumps {
+		if cerr := c.Close(); cerr != nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+func hostPort(host string) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	return net.JoinHostPort(host, "22")
+}
+
+// hashHost deterministically maps host to a non-negative int, used to
+// pick the jump host a target is tunneled through.
+func hashHost(host string) int {
+	h := 0
+	for _, c := range host {
+		h = h*31 + int(c)
+	}
+	if h < 0 {
+		h = -h
+	}
+	return h
+}
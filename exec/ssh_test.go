@@ -0,0 +1,46 @@
+package exec
+
+import "testing"
+
+func TestSSHSpecValidate(t *testing.T) {
+	cases := []struct {
+		Spec SSHSpec
+		Err  error
+	}{
+		{Spec: SSHSpec{}, Err: ErrNoSSHUser},
+		{Spec: SSHSpec{User: "root"}, Err: ErrNoKnownHosts},
+		{Spec: SSHSpec{User: "root", Insecure: true}, Err: nil},
+		{Spec: SSHSpec{User: "root", KnownHostsFile: "known_hosts"}, Err: nil},
+	}
+
+	for i, tc := range cases {
+		if err := tc.Spec.Validate(); err != tc.Err {
+			t.Fatalf("case %d: err = %v, want %v", i, err, tc.Err)
+		}
+	}
+}
+
+func TestHashHost(t *testing.T) {
+	if hashHost("a") < 0 {
+		t.Fatal("hashHost must never return a negative value")
+	}
+	if hashHost("host") != hashHost("host") {
+		t.Fatal("hashHost must be deterministic for the same input")
+	}
+}
+
+func TestHostPort(t *testing.T) {
+	cases := []struct {
+		In   string
+		Want string
+	}{
+		{In: "example.com", Want: "example.com:22"},
+		{In: "example.com:2222", Want: "example.com:2222"},
+	}
+
+	for i, tc := range cases {
+		if got := hostPort(tc.In); got != tc.Want {
+			t.Fatalf("case %d: got %q, want %q", i, got, tc.Want)
+		}
+	}
+}
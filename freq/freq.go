@@ -22,7 +22,7 @@ type Item struct {
 
 type resultsReader struct {
 	Sorter    func([]Item)
-	KeyReader func(*exec.Result) string
+	KeyReader KeyFunc
 }
 
 // Read reads JSON from the output of ush exec. Expects one exec.Result
@@ -80,11 +80,8 @@ func toFixed(num float64, precision int) float64 {
 // Duration returns a set of items grouped and sorted by duration distribution.
 func Duration(r io.Reader, truncate time.Duration) ([]Item, error) {
 	rr := resultsReader{
-		Sorter: func(items []Item) { sort.Sort(itemByDuration(items)) },
-		KeyReader: func(result *exec.Result) string {
-			d, _ := time.ParseDuration(result.Duration)
-			return time.Duration(d.Truncate(truncate) + truncate).String()
-		},
+		Sorter:    func(items []Item) { sort.Sort(itemByDuration(items)) },
+		KeyReader: DurationKey(truncate),
 	}
 	return rr.Read(r)
 }
@@ -103,7 +100,7 @@ func (t itemByDuration) Less(i, j int) bool {
 func ExitStatus(r io.Reader) ([]Item, error) {
 	rr := resultsReader{
 		Sorter:    func(items []Item) { sort.Sort(itemByExitStatus(items)) },
-		KeyReader: func(result *exec.Result) string { return strconv.Itoa(result.ExitStatus) },
+		KeyReader: ExitStatusKey,
 	}
 	return rr.Read(r)
 }
@@ -122,7 +119,7 @@ func (t itemByExitStatus) Less(i, j int) bool {
 func Stderr(r io.Reader) ([]Item, error) {
 	rr := resultsReader{
 		Sorter:    func(items []Item) { sort.Sort(itemByTargets(items)) },
-		KeyReader: func(result *exec.Result) string { return result.Stderr },
+		KeyReader: StderrKey,
 	}
 	return rr.Read(r)
 }
@@ -131,7 +128,7 @@ func Stderr(r io.Reader) ([]Item, error) {
 func Stdout(r io.Reader) ([]Item, error) {
 	rr := resultsReader{
 		Sorter:    func(items []Item) { sort.Sort(itemByTargets(items)) },
-		KeyReader: func(result *exec.Result) string { return result.Stdout },
+		KeyReader: StdoutKey,
 	}
 	return rr.Read(r)
 }
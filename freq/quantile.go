@@ -0,0 +1,225 @@
+package freq
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"ush/exec"
+)
+
+// compression bounds the number of centroids a digest keeps. Higher
+// values trade memory for accuracy; 100 is enough to keep p50/p95/p99
+// within a fraction of a percent on typical latency distributions.
+const compression = 100
+
+// centroid is a t-digest cluster: count samples averaging to mean.
+type centroid struct {
+	mean  float64
+	count float64
+}
+
+// tdigest is a streaming approximation of a distribution's quantiles,
+// after Dunning & Ertl's t-digest: samples are folded into a small set
+// of weighted centroids, packed more tightly near the tails (where
+// quantiles are most sensitive to error) than near the median, so
+// memory stays O(compression) instead of O(#samples).
+type tdigest struct {
+	centroids []centroid
+	count     float64
+	delta     float64
+}
+
+func newTDigest(delta float64) *tdigest {
+	return &tdigest{delta: delta}
+}
+
+// Add folds x into the digest: merges it into the nearest centroid that
+// still has potential room for one more sample, or inserts a new
+// centroid when none does.
+func (t *tdigest) Add(x float64) {
+	t.count++
+
+	if len(t.centroids) == 0 {
+		t.centroids = append(t.centroids, centroid{mean: x, count: 1})
+		return
+	}
+
+	i := sort.Search(len(t.centroids), func(i int) bool { return t.centroids[i].mean >= x })
+
+	best, bestDist := -1, math.Inf(1)
+	for _, j := range [2]int{i - 1, i} {
+		if j < 0 || j >= len(t.centroids) {
+			continue
+		}
+		if d := math.Abs(t.centroids[j].mean - x); d < bestDist {
+			best, bestDist = j, d
+		}
+	}
+
+	if best >= 0 && t.hasRoom(best) {
+		c := &t.centroids[best]
+		c.mean += (x - c.mean) / (c.count + 1)
+		c.count++
+	} else {
+		t.centroids = append(t.centroids, centroid{})
+		copy(t.centroids[i+1:], t.centroids[i:])
+		t.centroids[i] = centroid{mean: x, count: 1}
+	}
+
+	if len(t.centroids) > compression*2 {
+		t.compress()
+	}
+}
+
+// hasRoom reports whether centroid i can absorb one more sample without
+// its quantile potential, 4*N*q*(1-q)/delta, being exceeded, where q is
+// the quantile at its cumulative weight so far.
+func (t *tdigest) hasRoom(i int) bool {
+	var before float64
+	for _, c := range t.centroids[:i] {
+		before += c.count
+	}
+	q := (before + t.centroids[i].count/2) / t.count
+	limit := 4 * t.count * q * (1 - q) / t.delta
+	return t.centroids[i].count < limit
+}
+
+// compress greedily merges adjacent centroids, in mean order, while
+// they still have potential room, folding the centroid count back down
+// toward compression.
+func (t *tdigest) compress() {
+	if len(t.centroids) == 0 {
+		return
+	}
+
+	merged := make([]centroid, 0, compression)
+	merged = append(merged, t.centroids[0])
+
+	var before float64
+	for _, c := range t.centroids[1:] {
+		last := &merged[len(merged)-1]
+		q := (before + last.count/2) / t.count
+		limit := 4 * t.count * q * (1 - q) / t.delta
+
+		if last.count+c.count <= limit {
+			last.mean = (last.mean*last.count + c.mean*c.count) / (last.count + c.count)
+			last.count += c.count
+		} else {
+			before += last.count
+			merged = append(merged, c)
+		}
+	}
+
+	t.centroids = merged
+}
+
+// Quantile returns the approximate value at quantile q, in [0, 1].
+func (t *tdigest) Quantile(q float64) float64 {
+	switch len(t.centroids) {
+	case 0:
+		return 0
+	case 1:
+		return t.centroids[0].mean
+	}
+
+	target := q * t.count
+	var cum float64
+	for i, c := range t.centroids {
+		next := cum + c.count
+		if next >= target || i == len(t.centroids)-1 {
+			return c.mean
+		}
+		cum = next
+	}
+	return t.centroids[len(t.centroids)-1].mean
+}
+
+// Quantiles reads exec.Result durations from r and returns the
+// approximate duration at each quantile in qs (each in [0, 1]), using a
+// streaming t-digest so memory stays O(compression) rather than
+// O(#results).
+func Quantiles(r io.Reader, qs []float64) (map[float64]time.Duration, error) {
+	digest := newTDigest(compression)
+
+	dec := json.NewDecoder(r)
+	var err error
+	for dec.More() {
+		var result exec.Result
+		if err = dec.Decode(&result); err != nil {
+			break
+		}
+		if d, perr := time.ParseDuration(result.Duration); perr == nil {
+			digest.Add(float64(d))
+		}
+	}
+
+	return snapshotQuantiles(digest, qs), err
+}
+
+// DurationWithQuantiles is Duration and Quantiles combined into a single
+// pass over r, for callers (e.g. ush freq duration --quantiles) that
+// want both the truncated-bucket histogram and the percentile summary
+// from the same exec JSON stream, without reading it twice.
+func DurationWithQuantiles(r io.Reader, truncate time.Duration, qs []float64) ([]Item, map[float64]time.Duration, error) {
+	key := DurationKey(truncate)
+	digest := newTDigest(compression)
+
+	m := make(map[string][]string)
+	dec := json.NewDecoder(r)
+	var err error
+	var results int
+
+	for dec.More() {
+		var result exec.Result
+		if err = dec.Decode(&result); err != nil {
+			break
+		}
+
+		results++
+		m[key(&result)] = append(m[key(&result)], result.Target)
+
+		if d, perr := time.ParseDuration(result.Duration); perr == nil {
+			digest.Add(float64(d))
+		}
+	}
+
+	var items []Item
+	if len(m) > 0 {
+		items = make([]Item, 0, len(m))
+		for text, targets := range m {
+			items = append(items, Item{
+				Freq:    toFixed(float64(len(targets))*100/float64(results), 2),
+				Value:   text,
+				Targets: targets,
+			})
+		}
+		sort.Sort(itemByDuration(items))
+	}
+
+	return items, snapshotQuantiles(digest, qs), err
+}
+
+func snapshotQuantiles(digest *tdigest, qs []float64) map[float64]time.Duration {
+	quantiles := make(map[float64]time.Duration, len(qs))
+	for _, q := range qs {
+		quantiles[q] = time.Duration(digest.Quantile(q))
+	}
+	return quantiles
+}
+
+// EncodeQuantiles writes one "p<q*100> <duration>" line per q in qs
+// (e.g. "p50" for 0.5), in the order given, to w.
+func EncodeQuantiles(w io.Writer, qs []float64, quantiles map[float64]time.Duration) error {
+	for _, q := range qs {
+		p := strconv.FormatFloat(q*100, 'f', -1, 64)
+		if _, err := fmt.Fprintf(w, "p%-5s %s\n", p, quantiles[q]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package freq
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"ush/exec"
+)
+
+func TestQuantiles(t *testing.T) {
+	var results []exec.Result
+	for i := 1; i <= 100; i++ {
+		results = append(results, exec.Result{
+			Target:   "t",
+			Duration: (time.Duration(i) * time.Millisecond).String(),
+		})
+	}
+
+	quantiles, err := Quantiles(encodeResults(results), []float64{0.5, 0.99})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p50 := quantiles[0.5]; p50 < 40*time.Millisecond || p50 > 60*time.Millisecond {
+		t.Fatalf("p50 = %s, want close to 50ms", p50)
+	}
+	if p99 := quantiles[0.99]; p99 < 90*time.Millisecond || p99 > 100*time.Millisecond {
+		t.Fatalf("p99 = %s, want close to 99ms", p99)
+	}
+}
+
+func TestEncodeQuantiles(t *testing.T) {
+	var b bytes.Buffer
+	qs := []float64{0.5, 0.95}
+	quantiles := map[float64]time.Duration{
+		0.5:  10 * time.Millisecond,
+		0.95: 40 * time.Millisecond,
+	}
+
+	if err := EncodeQuantiles(&b, qs, quantiles); err != nil {
+		t.Fatal(err)
+	}
+
+	have := b.String()
+	want := "p50    10ms\np95    40ms\n"
+	if have != want {
+		t.Fatalf("unexpected buffer:\nwant: %q\nhave: %q\n", want, have)
+	}
+}
+
+func TestDurationWithQuantiles(t *testing.T) {
+	results := encodeResults([]exec.Result{
+		{Target: "a", Duration: "8ms"},
+		{Target: "b", Duration: "6ms"},
+		{Target: "c", Duration: "2ms"},
+	})
+
+	items, quantiles, err := DurationWithQuantiles(results, 5*time.Millisecond, []float64{0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items) != 2 {
+		t.Fatalf("unexpected number of items: want 2; have %d", len(items))
+	}
+	if _, ok := quantiles[0.5]; !ok {
+		t.Fatalf("missing p50 in quantiles: %v", quantiles)
+	}
+}
@@ -0,0 +1,159 @@
+package freq
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"golang.org/x/term"
+
+	"ush/exec"
+)
+
+// KeyFunc extracts the grouping key from a decoded exec.Result. It is
+// the streaming counterpart of resultsReader.KeyReader, shared so
+// cmd/freq* can drive Stream with the same grouping logic as the
+// non-streaming Duration, ExitStatus, Stderr and Stdout functions.
+type KeyFunc func(*exec.Result) string
+
+// DurationKey groups by duration truncated to truncate, like Duration.
+func DurationKey(truncate time.Duration) KeyFunc {
+	return func(result *exec.Result) string {
+		d, _ := time.ParseDuration(result.Duration)
+		return time.Duration(d.Truncate(truncate) + truncate).String()
+	}
+}
+
+// ExitStatusKey groups by exit status, like ExitStatus.
+func ExitStatusKey(result *exec.Result) string { return strconv.Itoa(result.ExitStatus) }
+
+// StderrKey groups by stderr, like Stderr.
+func StderrKey(result *exec.Result) string { return result.Stderr }
+
+// StdoutKey groups by stdout, like Stdout.
+func StdoutKey(result *exec.Result) string { return result.Stdout }
+
+// Stream decodes exec.Result records from src as they arrive, grouping
+// them by key the same way the non-streaming Read does, but without
+// waiting for src to be exhausted: every interval, and once more before
+// returning, it calls emit with the current snapshot sorted by Freq,
+// descending. Stream returns when src has no more to decode, ctx is
+// cancelled, or decoding a record fails.
+func Stream(ctx context.Context, src io.Reader, key KeyFunc, emit func([]Item), interval time.Duration) error {
+	dec := json.NewDecoder(src)
+
+	type decoded struct {
+		result exec.Result
+		err    error
+	}
+	next := make(chan decoded)
+	go func() {
+		defer close(next)
+		for dec.More() {
+			var d decoded
+			d.err = dec.Decode(&d.result)
+			select {
+			case next <- d:
+			case <-ctx.Done():
+				return
+			}
+			if d.err != nil {
+				return
+			}
+		}
+	}()
+
+	m := make(map[string][]string)
+	var results int
+
+	snapshot := func() {
+		if len(m) == 0 {
+			return
+		}
+		items := make([]Item, 0, len(m))
+		for text, targets := range m {
+			items = append(items, Item{
+				Freq:    toFixed(float64(len(targets))*100/float64(results), 2),
+				Value:   text,
+				Targets: append([]string(nil), targets...),
+			})
+		}
+		sort.Sort(itemByFreqDesc(items))
+		emit(items)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case d, ok := <-next:
+			if !ok {
+				snapshot()
+				return nil
+			}
+			if d.err != nil {
+				snapshot()
+				return d.err
+			}
+			results++
+			k := key(&d.result)
+			m[k] = append(m[k], d.result.Target)
+		case <-ticker.C:
+			snapshot()
+		case <-ctx.Done():
+			snapshot()
+			return ctx.Err()
+		}
+	}
+}
+
+type itemByFreqDesc []Item
+
+func (t itemByFreqDesc) Len() int      { return len(t) }
+func (t itemByFreqDesc) Swap(i, j int) { t[i], t[j] = t[j], t[i] }
+func (t itemByFreqDesc) Less(i, j int) bool {
+	if t[i].Freq != t[j].Freq {
+		return t[i].Freq > t[j].Freq
+	}
+	return t[i].Value < t[j].Value
+}
+
+// EncodeWideStream returns an emit func for Stream that reprints the
+// EncodeWide histogram in place, using ANSI cursor moves to redraw over
+// the previous snapshot when w is a terminal. When w is not a terminal
+// (e.g. redirected to a file or pipe), snapshots are appended one after
+// another instead, separated by a blank line.
+func EncodeWideStream(w io.Writer) func([]Item) {
+	tty := isTerminal(w)
+	lines := 0
+
+	return func(items []Item) {
+		var buf bytes.Buffer
+		EncodeWide(&buf, items)
+
+		if tty {
+			if lines > 0 {
+				fmt.Fprintf(w, "\x1b[%dA\x1b[J", lines)
+			}
+			lines = bytes.Count(buf.Bytes(), []byte("\n"))
+		} else if lines > 0 {
+			fmt.Fprintln(w)
+		} else {
+			lines = 1
+		}
+
+		w.Write(buf.Bytes())
+	}
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
@@ -0,0 +1,53 @@
+package freq
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"ush/exec"
+)
+
+func TestStream(t *testing.T) {
+	pr, pw := io.Pipe()
+
+	go func() {
+		defer pw.Close()
+		enc := encodeResults([]exec.Result{
+			{Target: "a", ExitStatus: 0},
+			{Target: "b", ExitStatus: 0},
+			{Target: "c", ExitStatus: 1},
+		})
+		io.Copy(pw, enc)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var mu sync.Mutex
+	var last []Item
+	emit := func(items []Item) {
+		mu.Lock()
+		defer mu.Unlock()
+		last = items
+	}
+
+	if err := Stream(ctx, pr, ExitStatusKey, emit, 10*time.Millisecond); err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(last) != 2 {
+		t.Fatalf("unexpected number of items: want 2; have %d", len(last))
+	}
+	total := 0
+	for _, item := range last {
+		total += len(item.Targets)
+	}
+	if total != 3 {
+		t.Fatalf("unexpected total targets: want 3; have %d", total)
+	}
+}
@@ -0,0 +1,112 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"ush/exec"
+	"ush/rpc/usv1"
+)
+
+// Client drives a remote Server over the ush.v1.Executor gRPC protocol.
+type Client struct {
+	conn *grpc.ClientConn
+	c    usv1.ExecutorClient
+}
+
+// Dial connects to a Server listening on the unix socket at addr, such as
+// one forwarded back from a jump host over ssh.
+func Dial(ctx context.Context, addr string) (*Client, error) {
+	conn, err := grpc.DialContext(ctx, addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", addr)
+		}),
+		grpc.WithBlock(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{conn: conn, c: usv1.NewExecutorClient(conn)}, nil
+}
+
+// Close closes the connection to the remote Server.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Run streams targets to the remote Server under runID and calls emit for
+// every RunEvent received. Closing targets only closes the sending side;
+// Run keeps receiving until the remote side closes the stream, or until
+// ctx is cancelled.
+func (c *Client) Run(ctx context.Context, runID string, targets <-chan string, emit func(*usv1.RunEvent)) error {
+	stream, err := c.c.Run(ctx)
+	if err != nil {
+		return err
+	}
+
+	sendErr := make(chan error, 1)
+	go func() {
+		first := true
+		for {
+			select {
+			case t, more := <-targets:
+				if !more {
+					sendErr <- stream.CloseSend()
+					return
+				}
+				req := &usv1.RunRequest{Target: t}
+				if first {
+					req.RunId = runID
+					first = false
+				}
+				if err := stream.Send(req); err != nil {
+					sendErr <- err
+					return
+				}
+			case <-ctx.Done():
+				sendErr <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			return <-sendErr
+		}
+		if err != nil {
+			return fmt.Errorf("run stream: %w", err)
+		}
+		emit(event)
+	}
+}
+
+// Cancel asks the remote Server to stop run runID.
+func (c *Client) Cancel(ctx context.Context, runID string) error {
+	_, err := c.c.Cancel(ctx, &usv1.CancelRequest{RunId: runID})
+	return err
+}
+
+// Accumulate folds a RunEvent into the exec.Result of the target it
+// belongs to, so a caller driving Run can reassemble the same shape of
+// result a local Runner would have produced.
+func Accumulate(res *exec.Result, event *usv1.RunEvent) {
+	switch event.Type {
+	case usv1.RunEvent_STDOUT_CHUNK:
+		res.Stdout += string(event.Data)
+	case usv1.RunEvent_STDERR_CHUNK:
+		res.Stderr += string(event.Data)
+	case usv1.RunEvent_EXIT, usv1.RunEvent_ERROR:
+		res.ExitStatus = int(event.ExitStatus)
+		res.Err = event.Error
+	}
+}
@@ -0,0 +1,9 @@
+// Package rpc implements the ush.v1.Executor gRPC control-plane protocol
+// used between the controller and `ush agent` running on a jump host.
+//
+// usv1/rpc.pb.go and usv1/rpc_grpc.pb.go are generated from rpc.proto and
+// committed alongside it; regenerate and commit both after editing the
+// proto file:
+//
+//go:generate protoc --go_out=. --go_opt=module=ush/rpc --go-grpc_out=. --go-grpc_opt=module=ush/rpc rpc.proto
+package rpc
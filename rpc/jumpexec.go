@@ -0,0 +1,196 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	ushexec "ush/exec"
+	"ush/rpc/usv1"
+)
+
+// JumpExec is the gRPC counterpart of exec.JumpExec: it still opens an ssh
+// session to each jump host, but instead of piping targets through its
+// stdin and parsing results back from its stdout, it forwards a Unix
+// socket back from a `ush agent` it starts on the jump host and drives
+// that agent over the ush.v1.Executor protocol, with real flow control,
+// per-target acks, and a Cancel RPC instead of hoping the child dies.
+func JumpExec(ctx context.Context, w io.Writer, s *ushexec.JumpSpec, targets <-chan string) error {
+	if s.JumpCommand == "" {
+		return fmt.Errorf("jump command not set")
+	}
+	if len(s.JumpHosts) == 0 {
+		return fmt.Errorf("no jump hosts available")
+	}
+
+	parallel := s.Parallel / len(s.JumpHosts)
+	if parallel == 0 {
+		parallel = 1
+	}
+
+	auditor := ushexec.AuditorOrNop(s.Auditor)
+
+	var mu sync.Mutex
+	g, ctx := errgroup.WithContext(ctx)
+
+	for _, host := range s.JumpHosts {
+		host := host
+
+		runID := fmt.Sprintf("%s-%d", strings.Replace(host, "/", "_", -1), os.Getpid())
+		localSocket := filepath.Join(os.TempDir(), "ush-agent-"+runID+".sock")
+		remoteSocket := "/tmp/ush-agent-" + runID + ".sock"
+
+		cmd := strings.Replace(s.JumpCommand, "{.J}", host, -1)
+		args := strings.Split(cmd, " ")
+		cmd, args = args[0], args[1:]
+		args = append(args, "-L", localSocket+":"+remoteSocket)
+
+		agentArgs := []string{
+			"--",
+			"ush",
+			"agent",
+			"--socket=" + remoteSocket,
+			"--timeout=" + s.Timeout.String(),
+			"--parallel=" + strconv.Itoa(parallel),
+			"--stdout_bytes=" + strconv.Itoa(s.StdoutBytes),
+			"--stderr_bytes=" + strconv.Itoa(s.StderrBytes),
+			"--",
+			s.Command,
+		}
+		args = append(args, agentArgs...)
+		args = append(args, s.Args...)
+
+		oscmd := exec.CommandContext(ctx, cmd, args...)
+		oscmd.Stderr = os.Stderr
+
+		if err := oscmd.Start(); err != nil {
+			return fmt.Errorf("jump host %s: %v", host, err)
+		}
+
+		client, err := dialAgent(ctx, localSocket)
+		if err != nil {
+			oscmd.Process.Kill()
+			return fmt.Errorf("jump host %s: %v", host, err)
+		}
+
+		g.Go(func() error {
+			defer oscmd.Process.Kill()
+			defer client.Close()
+			defer os.Remove(localSocket)
+			return driveJumpHost(ctx, w, &mu, client, host, runID, auditor, targets)
+		})
+	}
+
+	return g.Wait()
+}
+
+// dialAgent retries Dial until the agent's forwarded socket shows up or
+// ctx is done; `ush agent` takes a moment to start listening after ssh
+// establishes the forward.
+func dialAgent(ctx context.Context, socket string) (*Client, error) {
+	for {
+		client, err := Dial(ctx, socket)
+		if err == nil {
+			return client, nil
+		}
+		select {
+		case <-time.After(100 * time.Millisecond):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// driveJumpHost streams targets to client, reassembles each target's
+// RunEvents into an ushexec.Result, and encodes it to w as it finishes -
+// the same output shape exec.JumpExec produces with the legacy transport.
+//
+// It also proxies each target's lifecycle into auditor: `ush agent` runs
+// on the jump host with no Auditor of its own (there would be nowhere for
+// the controller to read its audit trail from), so driveJumpHost emits
+// EventTargetStarted/EventTargetFinished and records the session itself,
+// from the RunEvents it already receives over the RunEvent stream. The
+// recorded output is the byte-capped output the agent sends back.
+//
+// If ctx is cancelled while the run is in flight, it sends a Cancel RPC so
+// the agent can stop the in-progress targets and exit cleanly instead of
+// relying solely on JumpExec killing the local ssh tunnel process.
+func driveJumpHost(ctx context.Context, w io.Writer, mu *sync.Mutex, client *Client, host, runID string, auditor ushexec.Auditor, targets <-chan string) error {
+	var resultsMu sync.Mutex
+	results := make(map[string]*ushexec.Result)
+	sessions := make(map[string]io.WriteCloser)
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			cancelCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			client.Cancel(cancelCtx, runID)
+		case <-done:
+		}
+	}()
+
+	return client.Run(ctx, runID, targets, func(event *usv1.RunEvent) {
+		resultsMu.Lock()
+		res, ok := results[event.Target]
+		if !ok {
+			res = &ushexec.Result{Target: event.Target, StartTime: time.Now()}
+			results[event.Target] = res
+
+			session, err := auditor.RecordSession(event.Target)
+			if err == nil {
+				sessions[event.Target] = session
+			}
+
+			auditor.Emit(ushexec.Event{
+				Type: ushexec.EventTargetStarted, Time: res.StartTime,
+				Target: event.Target, JumpHost: host,
+			})
+		}
+		Accumulate(res, event)
+
+		if session, ok := sessions[event.Target]; ok {
+			switch event.Type {
+			case usv1.RunEvent_STDOUT_CHUNK, usv1.RunEvent_STDERR_CHUNK:
+				session.Write(event.Data)
+			}
+		}
+
+		finished := event.Type == usv1.RunEvent_EXIT || event.Type == usv1.RunEvent_ERROR
+		if finished {
+			res.EndTime = time.Now()
+			res.Duration = res.EndTime.Sub(res.StartTime).String()
+			delete(results, event.Target)
+
+			if session, ok := sessions[event.Target]; ok {
+				session.Close()
+				delete(sessions, event.Target)
+			}
+
+			auditor.Emit(ushexec.Event{
+				Type: ushexec.EventTargetFinished, Time: res.EndTime,
+				Target: event.Target, JumpHost: host,
+				ExitStatus: res.ExitStatus, Duration: res.Duration, Err: res.Err,
+			})
+		}
+		resultsMu.Unlock()
+
+		if finished {
+			mu.Lock()
+			json.NewEncoder(w).Encode(res)
+			mu.Unlock()
+		}
+	})
+}
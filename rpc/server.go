@@ -0,0 +1,172 @@
+package rpc
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"google.golang.org/grpc"
+
+	"ush/exec"
+	"ush/rpc/usv1"
+)
+
+// Server implements usv1.ExecutorServer on top of an exec.Runner, so a jump
+// host can be driven over gRPC instead of having targets piped through its
+// stdin and results parsed back from its stdout.
+type Server struct {
+	usv1.UnimplementedExecutorServer
+
+	spec   *exec.Spec
+	runner exec.Runner
+
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc
+}
+
+// NewServer returns a Server that executes every run with spec, using
+// runner to run each target. A nil runner defaults to exec.LocalRunner{}.
+func NewServer(spec *exec.Spec, runner exec.Runner) *Server {
+	if runner == nil {
+		runner = exec.LocalRunner{}
+	}
+	return &Server{
+		spec:    spec,
+		runner:  runner,
+		cancels: make(map[string]context.CancelFunc),
+	}
+}
+
+// RegisterExecutor registers a Server for spec and runner on g.
+func RegisterExecutor(g *grpc.Server, spec *exec.Spec, runner exec.Runner) {
+	usv1.RegisterExecutorServer(g, NewServer(spec, runner))
+}
+
+// Run implements usv1.ExecutorServer. It reads targets off stream until
+// the controller closes it or the run is cancelled, running up to
+// s.spec.Parallel of them at once and streaming a RunEvent for each
+// lifecycle transition.
+func (s *Server) Run(stream usv1.Executor_RunServer) error {
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	runID := first.RunId
+	ctx, cancel := context.WithCancel(stream.Context())
+	s.addCancel(runID, cancel)
+	defer s.removeCancel(runID)
+	defer cancel()
+
+	targets := make(chan string)
+	recvErr := make(chan error, 1)
+
+	go func() {
+		defer close(targets)
+		targets <- first.Target
+		for {
+			req, err := stream.Recv()
+			if err == io.EOF {
+				recvErr <- nil
+				return
+			}
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			select {
+			case targets <- req.Target:
+			case <-ctx.Done():
+				recvErr <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	var sendMu sync.Mutex
+	send := func(event *usv1.RunEvent) error {
+		event.RunId = runID
+		sendMu.Lock()
+		defer sendMu.Unlock()
+		return stream.Send(event)
+	}
+
+	parallel := s.spec.Parallel
+	if parallel <= 0 {
+		parallel = 1
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, parallel)
+
+	for t := range targets {
+		t := t
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			s.runOne(ctx, t, send)
+		}()
+	}
+
+	wg.Wait()
+
+	if err := <-recvErr; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) runOne(ctx context.Context, target string, send func(*usv1.RunEvent) error) {
+	send(&usv1.RunEvent{Target: target, Type: usv1.RunEvent_STARTED})
+
+	result := s.runner.Run(ctx, s.spec, target)
+
+	if result.Stdout != "" {
+		send(&usv1.RunEvent{Target: target, Type: usv1.RunEvent_STDOUT_CHUNK, Data: []byte(result.Stdout)})
+	}
+	if result.Stderr != "" {
+		send(&usv1.RunEvent{Target: target, Type: usv1.RunEvent_STDERR_CHUNK, Data: []byte(result.Stderr)})
+	}
+
+	event := &usv1.RunEvent{
+		Target:     target,
+		Type:       usv1.RunEvent_EXIT,
+		ExitStatus: int32(result.ExitStatus),
+	}
+	if result.Err != "" {
+		event.Type = usv1.RunEvent_ERROR
+		event.Error = result.Err
+	}
+	send(event)
+}
+
+// Cancel implements usv1.ExecutorServer. It stops accepting new targets
+// and kills any target in flight for the given run.
+func (s *Server) Cancel(ctx context.Context, req *usv1.CancelRequest) (*usv1.CancelResponse, error) {
+	s.mu.Lock()
+	cancel, ok := s.cancels[req.RunId]
+	s.mu.Unlock()
+
+	if ok {
+		cancel()
+	}
+
+	return &usv1.CancelResponse{}, nil
+}
+
+func (s *Server) addCancel(runID string, cancel context.CancelFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cancels[runID] = cancel
+}
+
+func (s *Server) removeCancel(runID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cancels, runID)
+}
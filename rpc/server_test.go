@@ -0,0 +1,117 @@
+package rpc
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"ush/exec"
+	"ush/rpc/usv1"
+)
+
+func startTestServer(t *testing.T, spec *exec.Spec, runner exec.Runner) (*Client, func()) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "ush-rpc-test")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+
+	socket := filepath.Join(dir, "agent.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("Listen: %v", err)
+	}
+
+	g := grpc.NewServer()
+	RegisterExecutor(g, spec, runner)
+	go g.Serve(l)
+
+	client, err := Dial(context.Background(), socket)
+	if err != nil {
+		g.Stop()
+		os.RemoveAll(dir)
+		t.Fatalf("Dial: %v", err)
+	}
+
+	return client, func() {
+		client.Close()
+		g.Stop()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestServerRun(t *testing.T) {
+	spec := &exec.Spec{Command: "echo", Args: []string{"{.T}"}, Timeout: time.Second, Parallel: 2, StdoutBytes: 1024, StderrBytes: 1024}
+	client, stop := startTestServer(t, spec, exec.LocalRunner{})
+	defer stop()
+
+	targets := make(chan string, 1)
+	targets <- "hello"
+	close(targets)
+
+	results := make(map[string]*exec.Result)
+	err := client.Run(context.Background(), "run-1", targets, func(event *usv1.RunEvent) {
+		res, ok := results[event.Target]
+		if !ok {
+			res = &exec.Result{Target: event.Target}
+			results[event.Target] = res
+		}
+		Accumulate(res, event)
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	res, ok := results["hello"]
+	if !ok {
+		t.Fatalf("no result for target %q", "hello")
+	}
+	if res.ExitStatus != 0 {
+		t.Fatalf("unexpected exit status: %d", res.ExitStatus)
+	}
+	if res.Stdout == "" {
+		t.Fatalf("expected stdout to be recorded")
+	}
+}
+
+func TestServerCancel(t *testing.T) {
+	spec := &exec.Spec{Command: "sleep", Args: []string{"5"}, Timeout: 10 * time.Second, Parallel: 1, StdoutBytes: 1024, StderrBytes: 1024}
+	client, stop := startTestServer(t, spec, exec.LocalRunner{})
+	defer stop()
+
+	targets := make(chan string)
+
+	runDone := make(chan error, 1)
+	go func() {
+		runDone <- client.Run(context.Background(), "run-2", targets, func(event *usv1.RunEvent) {})
+	}()
+
+	targets <- "target"
+
+	// give the server a moment to start running the target before
+	// cancelling, so Cancel exercises the in-flight path rather than
+	// racing Run's first Recv.
+	time.Sleep(100 * time.Millisecond)
+	close(targets)
+
+	if err := client.Cancel(context.Background(), "run-2"); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+
+	select {
+	case err := <-runDone:
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after Cancel")
+	}
+}
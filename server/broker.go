@@ -0,0 +1,120 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"sync"
+	"time"
+)
+
+// DefaultLeaseTimeout is how long a batch of targets handed out by NEXT
+// stays checked out before broker automatically returns it to the pool.
+const DefaultLeaseTimeout = 30 * time.Second
+
+// broker divides a cache's content, one target per line, into a pool
+// that multiple executors can cooperatively check batches out of with
+// Next, and mark done with Ack, or return early with Nack. A target
+// that is checked out but neither acked nor nacked within lease is
+// reclaimed and handed out again.
+type broker struct {
+	lease time.Duration
+
+	mu      sync.Mutex
+	all     []string
+	pending []string
+	leased  map[string]time.Time
+}
+
+func newBroker(cache *Cache, lease time.Duration) *broker {
+	var targets []string
+	scanner := bufio.NewScanner(bytes.NewReader(cache.Bytes()))
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			targets = append(targets, line)
+		}
+	}
+
+	b := &broker{lease: lease, leased: make(map[string]time.Time)}
+	b.assign(targets)
+	return b
+}
+
+func (b *broker) assign(targets []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.all = targets
+	b.pending = append([]string(nil), targets...)
+	b.leased = make(map[string]time.Time)
+}
+
+// Reset returns every target, checked out or not, to the pool.
+func (b *broker) Reset() {
+	b.assign(b.all)
+}
+
+// Len returns the number of targets left to check out.
+func (b *broker) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.reclaimExpired()
+	return len(b.pending)
+}
+
+// Next checks out up to n pending targets and returns them. Checked out
+// targets are held for b.lease, after which they return to the pool
+// automatically unless Ack or Nack is called first.
+func (b *broker) Next(n int) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.reclaimExpired()
+
+	if n > len(b.pending) {
+		n = len(b.pending)
+	}
+	out := append([]string(nil), b.pending[:n]...)
+	b.pending = b.pending[n:]
+
+	deadline := time.Now().Add(b.lease)
+	for _, t := range out {
+		b.leased[t] = deadline
+	}
+	return out
+}
+
+// Ack marks targets as done, removing them from the pool for good.
+func (b *broker) Ack(targets []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, t := range targets {
+		delete(b.leased, t)
+	}
+}
+
+// Nack returns checked out targets to the pending pool immediately.
+func (b *broker) Nack(targets []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, t := range targets {
+		if _, ok := b.leased[t]; ok {
+			delete(b.leased, t)
+			b.pending = append(b.pending, t)
+		}
+	}
+}
+
+// reclaimExpired returns targets whose lease has passed to the pool.
+// Callers must hold b.mu.
+func (b *broker) reclaimExpired() {
+	now := time.Now()
+	for t, deadline := range b.leased {
+		if now.After(deadline) {
+			delete(b.leased, t)
+			b.pending = append(b.pending, t)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func testCache(t *testing.T, content string) *Cache {
+	t.Helper()
+	f := createTempFile([]byte(content))
+	defer os.Remove(f)
+
+	c, err := NewCache(f)
+	if err != nil {
+		t.Fatalf("NewCache: %v", err)
+	}
+	return c
+}
+
+func TestBrokerNextAckNack(t *testing.T) {
+	b := newBroker(testCache(t, "a\nb\nc\n"), time.Minute)
+
+	if got := b.Len(); got != 3 {
+		t.Fatalf("Len() = %d, want 3", got)
+	}
+
+	got := b.Next(2)
+	if len(got) != 2 {
+		t.Fatalf("Next(2) returned %v", got)
+	}
+	if b.Len() != 1 {
+		t.Fatalf("Len() = %d after checkout, want 1", b.Len())
+	}
+
+	b.Nack(got[:1])
+	if b.Len() != 2 {
+		t.Fatalf("Len() = %d after Nack, want 2", b.Len())
+	}
+
+	b.Ack(got[1:])
+	rest := b.Next(10)
+	if len(rest) != 2 {
+		t.Fatalf("Next(10) = %v, want 2 remaining targets", rest)
+	}
+}
+
+func TestBrokerLeaseExpiry(t *testing.T) {
+	b := newBroker(testCache(t, "a\nb\n"), time.Millisecond)
+
+	out := b.Next(2)
+	if len(out) != 2 {
+		t.Fatalf("Next(2) = %v", out)
+	}
+	if b.Len() != 0 {
+		t.Fatalf("Len() = %d right after checkout, want 0", b.Len())
+	}
+
+	time.Sleep(5 * time.Millisecond)
+
+	if b.Len() != 2 {
+		t.Fatalf("Len() = %d after lease expiry, want 2", b.Len())
+	}
+}
+
+func TestBrokerReset(t *testing.T) {
+	b := newBroker(testCache(t, "a\nb\n"), time.Minute)
+
+	b.Next(2)
+	b.Reset()
+
+	if b.Len() != 2 {
+		t.Fatalf("Len() = %d after Reset, want 2", b.Len())
+	}
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+)
+
+// Cache reads an origin - a local file path or a remote address in the
+// form host:port - once, and keeps its content in memory, so repeated
+// consumers (e.g. serving the same file to many connections, or
+// uploading it to many targets) don't re-read or re-fetch it.
+type Cache struct {
+	content []byte
+}
+
+// NewCache reads origin and returns a Cache of its content.
+func NewCache(origin string) (*Cache, error) {
+	var f io.ReadCloser
+	var err error
+
+	if isHostPort(origin) {
+		f, err = net.Dial("tcp", origin)
+	} else {
+		f, err = os.Open(origin)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	content, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cache{content: content}, nil
+}
+
+// Bytes returns the cached content.
+func (c *Cache) Bytes() []byte {
+	return c.content
+}
@@ -0,0 +1,30 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestNewCache(t *testing.T) {
+	content := []byte("cache me once")
+	l, openPort := createTestTCPListener(content)
+	defer l.Close()
+
+	f := createTempFile(content)
+	defer os.Remove(f)
+
+	for _, origin := range []string{f, fmt.Sprintf("localhost:%d", openPort)} {
+		c, err := NewCache(origin)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %v", origin, err)
+		}
+		if string(c.Bytes()) != string(content) {
+			t.Fatalf("unexpected cache content for %q: %q", origin, c.Bytes())
+		}
+	}
+
+	if _, err := NewCache("random-file"); err == nil {
+		t.Fatal("expected error for missing file")
+	}
+}
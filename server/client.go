@@ -0,0 +1,88 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"ush/server/proto"
+)
+
+// Client drives the command protocol in server/proto against a server
+// listening in broker mode (NewServer, not NewLegacyServer).
+type Client struct {
+	conn net.Conn
+	r    *proto.Reader
+	w    *proto.Writer
+}
+
+// Dial connects to a broker server listening at addr.
+func Dial(addr string) (*Client, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn, r: proto.NewReader(conn), w: proto.NewWriter(conn)}, nil
+}
+
+// Close closes the underlying connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Len returns the number of targets left to check out.
+func (c *Client) Len() (int, error) {
+	kind, val, _, err := c.call("LEN")
+	if err != nil {
+		return 0, err
+	}
+	if kind != ':' {
+		return 0, fmt.Errorf("server: LEN: unexpected reply %c%s", kind, val)
+	}
+	return strconv.Atoi(val)
+}
+
+// Next checks out up to n targets.
+func (c *Client) Next(n int) ([]string, error) {
+	kind, val, items, err := c.call("NEXT", strconv.Itoa(n))
+	if err != nil {
+		return nil, err
+	}
+	if kind != '*' {
+		return nil, fmt.Errorf("server: NEXT: unexpected reply %c%s", kind, val)
+	}
+	return items, nil
+}
+
+// Ack marks targets as done.
+func (c *Client) Ack(targets ...string) error {
+	return c.simple("ACK", targets...)
+}
+
+// Nack returns checked out targets to the pool immediately.
+func (c *Client) Nack(targets ...string) error {
+	return c.simple("NACK", targets...)
+}
+
+// Reset returns every target, checked out or not, to the pool.
+func (c *Client) Reset() error {
+	return c.simple("RESET")
+}
+
+func (c *Client) simple(cmd string, args ...string) error {
+	kind, val, _, err := c.call(cmd, args...)
+	if err != nil {
+		return err
+	}
+	if kind == '-' {
+		return fmt.Errorf("server: %s: %s", cmd, val)
+	}
+	return nil
+}
+
+func (c *Client) call(cmd string, args ...string) (kind byte, val string, items []string, err error) {
+	if err := c.w.WriteCommand(append([]string{cmd}, args...)...); err != nil {
+		return 0, "", nil, err
+	}
+	return c.r.ReadReply()
+}
@@ -0,0 +1,176 @@
+// Package proto implements a small line-oriented command protocol,
+// inspired by RESP (the Redis Serialization Protocol): a command is a
+// multibulk array, "*<n>\r\n" followed by n bulk strings of the form
+// "$<len>\r\n<bytes>\r\n". Replies use the same bulk array encoding for
+// lists of values, plus one-line "+<msg>", "-<msg>" and ":<n>" replies
+// for simple acks, errors and integers.
+package proto
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Reader reads commands and bulk array replies off the wire. Reader is
+// not safe for concurrent use.
+type Reader struct {
+	br *bufio.Reader
+}
+
+// NewReader returns a Reader that reads from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{br: bufio.NewReader(r)}
+}
+
+// ReadCommand reads one multibulk array and returns its elements, e.g.
+// ["NEXT", "10"].
+func (r *Reader) ReadCommand() ([]string, error) {
+	return r.readArray()
+}
+
+// ReadArray reads one multibulk array reply.
+func (r *Reader) ReadArray() ([]string, error) {
+	return r.readArray()
+}
+
+func (r *Reader) readArray() ([]string, error) {
+	line, err := r.readLine()
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 || line[0] != '*' {
+		return nil, fmt.Errorf("proto: expected '*', got %q", line)
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return nil, fmt.Errorf("proto: invalid array length %q: %v", line[1:], err)
+	}
+
+	args := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		bulk, err := r.readBulk()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, bulk)
+	}
+	return args, nil
+}
+
+func (r *Reader) readBulk() (string, error) {
+	line, err := r.readLine()
+	if err != nil {
+		return "", err
+	}
+	if len(line) == 0 || line[0] != '$' {
+		return "", fmt.Errorf("proto: expected '$', got %q", line)
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return "", fmt.Errorf("proto: invalid bulk length %q: %v", line[1:], err)
+	}
+
+	buf := make([]byte, n+2) // payload plus trailing \r\n
+	if _, err := io.ReadFull(r.br, buf); err != nil {
+		return "", err
+	}
+	return string(buf[:n]), nil
+}
+
+// ReadReply reads one reply line: a bulk array ('*'), a simple string
+// ('+'), an error ('-') or an integer (':'). kind is the leading byte,
+// and val holds the simple string, error message or integer as text;
+// for arrays val is empty and items holds the elements.
+func (r *Reader) ReadReply() (kind byte, val string, items []string, err error) {
+	line, err := r.peekLine()
+	if err != nil {
+		return 0, "", nil, err
+	}
+	if len(line) == 0 {
+		return 0, "", nil, fmt.Errorf("proto: empty reply line")
+	}
+
+	switch line[0] {
+	case '*':
+		items, err = r.readArray()
+		return '*', "", items, err
+	case '+', '-', ':':
+		line, err := r.readLine()
+		if err != nil {
+			return 0, "", nil, err
+		}
+		return line[0], line[1:], nil, nil
+	default:
+		return 0, "", nil, fmt.Errorf("proto: unknown reply type %q", line[0])
+	}
+}
+
+func (r *Reader) peekLine() (string, error) {
+	b, err := r.br.Peek(1)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (r *Reader) readLine() (string, error) {
+	line, err := r.br.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// Writer writes commands and replies in the wire format Reader parses.
+type Writer struct {
+	w io.Writer
+}
+
+// NewWriter returns a Writer that writes to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// WriteCommand writes args as a multibulk array command.
+func (w *Writer) WriteCommand(args ...string) error {
+	return w.WriteArray(args)
+}
+
+// WriteArray writes items as a multibulk array reply.
+func (w *Writer) WriteArray(items []string) error {
+	if _, err := fmt.Fprintf(w.w, "*%d\r\n", len(items)); err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := w.writeBulk(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Writer) writeBulk(s string) error {
+	_, err := fmt.Fprintf(w.w, "$%d\r\n%s\r\n", len(s), s)
+	return err
+}
+
+// WriteSimple writes a one-line "+<s>" reply, e.g. for "OK" acks.
+func (w *Writer) WriteSimple(s string) error {
+	_, err := fmt.Fprintf(w.w, "+%s\r\n", s)
+	return err
+}
+
+// WriteError writes a one-line "-<s>" reply.
+func (w *Writer) WriteError(s string) error {
+	_, err := fmt.Fprintf(w.w, "-%s\r\n", s)
+	return err
+}
+
+// WriteInt writes a ":<n>" integer reply.
+func (w *Writer) WriteInt(n int) error {
+	_, err := fmt.Fprintf(w.w, ":%d\r\n", n)
+	return err
+}
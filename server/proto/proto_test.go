@@ -0,0 +1,55 @@
+package proto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCommandRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewWriter(&buf).WriteCommand("NEXT", "10"); err != nil {
+		t.Fatalf("WriteCommand: %v", err)
+	}
+
+	args, err := NewReader(&buf).ReadCommand()
+	if err != nil {
+		t.Fatalf("ReadCommand: %v", err)
+	}
+	if len(args) != 2 || args[0] != "NEXT" || args[1] != "10" {
+		t.Fatalf("unexpected args: %v", args)
+	}
+}
+
+func TestReplyRoundTrip(t *testing.T) {
+	for _, c := range []struct {
+		name  string
+		write func(w *Writer) error
+		kind  byte
+		val   string
+		items []string
+	}{
+		{"array", func(w *Writer) error { return w.WriteArray([]string{"a.example.com", "b.example.com"}) }, '*', "", []string{"a.example.com", "b.example.com"}},
+		{"empty array", func(w *Writer) error { return w.WriteArray(nil) }, '*', "", nil},
+		{"simple", func(w *Writer) error { return w.WriteSimple("OK") }, '+', "OK", nil},
+		{"error", func(w *Writer) error { return w.WriteError("unknown command") }, '-', "unknown command", nil},
+		{"int", func(w *Writer) error { return w.WriteInt(42) }, ':', "42", nil},
+	} {
+		var buf bytes.Buffer
+		if err := c.write(NewWriter(&buf)); err != nil {
+			t.Fatalf("%s: write: %v", c.name, err)
+		}
+
+		kind, val, items, err := NewReader(&buf).ReadReply()
+		if err != nil {
+			t.Fatalf("%s: ReadReply: %v", c.name, err)
+		}
+		if kind != c.kind || val != c.val || len(items) != len(c.items) {
+			t.Fatalf("%s: got (%c, %q, %v), want (%c, %q, %v)", c.name, kind, val, items, c.kind, c.val, c.items)
+		}
+		for i := range items {
+			if items[i] != c.items[i] {
+				t.Fatalf("%s: item %d: got %q, want %q", c.name, i, items[i], c.items[i])
+			}
+		}
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io"
-	"io/ioutil"
 	"net"
-	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"ush/server/proto"
 )
 
 var (
@@ -22,50 +24,64 @@ type Server interface {
 	Serve(context.Context) (errChan <-chan error, err error)
 }
 
-// server actual implementation
+// server speaks the command protocol in server/proto: the cached content
+// is split into lines and handed out as a work queue that connecting
+// clients check batches out of with NEXT, instead of every connection
+// receiving a full dump of the content. Set legacy to get the original
+// behavior back, e.g. via NewLegacyServer, or send a MODE dump command
+// on a connection to the non-legacy server.
+//
 // fileOrigin is a path to the local file or address in the form of address:port
-// bindAddress where local TCP server is listening
-// content caches file content to avoid refetching the file content on every connection
+// bindAddress is where the local TCP server listens
+// cache caches file content to avoid refetching it on every connection
 type server struct {
 	fileOrigin  string
 	bindAddress string
+	legacy      bool
+	lease       time.Duration
 
 	listener *net.TCPListener
-	content  []byte
+	cache    *Cache
+	broker   *broker
 }
 
 // NewServer does validation on fileOrigin and bindAddress passed arguments
 // fileOrigin should be a valid address of format host:port or path to the file
 // bindAddress should be a valid address of format host:port
+//
+// The returned Server speaks the command protocol in server/proto: see
+// NewLegacyServer for the original whole-file-dump behavior.
 func NewServer(fileOrigin, bindAddress string) (Server, error) {
-	if !isHostPort(bindAddress) {
-		return nil, fmt.Errorf("Invalid bind address: %s", bindAddress)
-	}
-
-	s := &server{
-		fileOrigin:  fileOrigin,
-		bindAddress: bindAddress,
-	}
+	return newServer(fileOrigin, bindAddress, false)
+}
 
-	var f io.ReadCloser
-	var err error
+// NewLegacyServer returns a Server that writes its whole cached content
+// to every connection and closes it, the behavior ush used before the
+// server/proto command protocol was added.
+func NewLegacyServer(fileOrigin, bindAddress string) (Server, error) {
+	return newServer(fileOrigin, bindAddress, true)
+}
 
-	if isHostPort(fileOrigin) {
-		f, err = net.Dial("tcp", fileOrigin)
-	} else {
-		f, err = os.Open(s.fileOrigin)
+func newServer(fileOrigin, bindAddress string, legacy bool) (Server, error) {
+	if !isHostPort(bindAddress) {
+		return nil, fmt.Errorf("Invalid bind address: %s", bindAddress)
 	}
 
+	cache, err := NewCache(fileOrigin)
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
 
-	s.content, err = ioutil.ReadAll(f)
-	if err != nil {
-		return nil, err
+	s := &server{
+		fileOrigin:  fileOrigin,
+		bindAddress: bindAddress,
+		legacy:      legacy,
+		lease:       DefaultLeaseTimeout,
+		cache:       cache,
+	}
+	if !legacy {
+		s.broker = newBroker(cache, s.lease)
 	}
-
 	return s, nil
 }
 
@@ -100,12 +116,7 @@ func (s *server) Serve(ctx context.Context) (<-chan error, error) {
 					errChan <- ErrNetConnAccept
 					return
 				}
-				go func() {
-					if _, err := conn.Write(s.content); err != nil {
-						errChan <- ErrNetConnWrite
-					}
-					conn.Close()
-				}()
+				go s.handle(conn, errChan)
 			}
 		}
 	}()
@@ -113,6 +124,75 @@ func (s *server) Serve(ctx context.Context) (<-chan error, error) {
 	return errChan, nil
 }
 
+// handle serves one connection, either dumping the whole cache (legacy
+// mode, or MODE dump on a broker-mode connection) or speaking the
+// command protocol in server/proto until the client disconnects.
+func (s *server) handle(conn net.Conn, errChan chan<- error) {
+	defer conn.Close()
+
+	if s.legacy {
+		if _, err := conn.Write(s.cache.Bytes()); err != nil {
+			errChan <- ErrNetConnWrite
+		}
+		return
+	}
+
+	r := proto.NewReader(conn)
+	w := proto.NewWriter(conn)
+
+	for {
+		args, err := r.ReadCommand()
+		if err != nil {
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		if writeErr := s.dispatch(conn, w, args); writeErr != nil {
+			errChan <- ErrNetConnWrite
+			return
+		}
+		if strings.EqualFold(args[0], "MODE") {
+			return // MODE dump hands the connection the whole cache and closes it
+		}
+	}
+}
+
+// dispatch runs one command against s.broker and writes its reply.
+func (s *server) dispatch(conn net.Conn, w *proto.Writer, args []string) error {
+	switch strings.ToUpper(args[0]) {
+	case "MODE":
+		if len(args) != 2 || !strings.EqualFold(args[1], "dump") {
+			return w.WriteError("unknown mode")
+		}
+		_, err := conn.Write(s.cache.Bytes())
+		return err
+	case "LEN":
+		return w.WriteInt(s.broker.Len())
+	case "NEXT":
+		if len(args) != 2 {
+			return w.WriteError("NEXT requires <n>")
+		}
+		n, err := strconv.Atoi(args[1])
+		if err != nil {
+			return w.WriteError("NEXT: invalid n")
+		}
+		return w.WriteArray(s.broker.Next(n))
+	case "ACK":
+		s.broker.Ack(args[1:])
+		return w.WriteSimple("OK")
+	case "NACK":
+		s.broker.Nack(args[1:])
+		return w.WriteSimple("OK")
+	case "RESET":
+		s.broker.Reset()
+		return w.WriteSimple("OK")
+	default:
+		return w.WriteError(fmt.Sprintf("unknown command %q", args[0]))
+	}
+}
+
 func isHostPort(hostport string) bool {
 	_, err := net.ResolveTCPAddr("tcp", hostport)
 	return err == nil
@@ -64,8 +64,8 @@ func TestNewServer(t *testing.T) {
 			continue
 		}
 		scast := s.(*server)
-		if string(scast.content) != string(content) {
-			t.Errorf("Incorrectly cached content. Expected: %s, got: %s", string(content), string(scast.content))
+		if string(scast.cache.Bytes()) != string(content) {
+			t.Errorf("Incorrectly cached content. Expected: %s, got: %s", string(content), string(scast.cache.Bytes()))
 		}
 	}
 }
@@ -73,6 +73,65 @@ func TestNewServer(t *testing.T) {
 func TestServe(t *testing.T) {
 	t.Run("Test for file via proxy", testServeForFile)
 	t.Run("Test network failure", testServeForFailure)
+	t.Run("Test broker protocol", testServeForBroker)
+}
+
+func testServeForBroker(t *testing.T) {
+	f := createTempFile([]byte("a\nb\nc\n"))
+	defer os.Remove(f)
+
+	s, err := NewServer(f, "localhost:0")
+	if err != nil {
+		t.Fatalf("Unexpected error when creating server object: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if _, err := s.Serve(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	port := s.(*server).listener.Addr().(*net.TCPAddr).Port
+	c, err := Dial(fmt.Sprintf("localhost:%d", port))
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer c.Close()
+
+	if n, err := c.Len(); err != nil || n != 3 {
+		t.Fatalf("Len() = (%d, %v), want (3, nil)", n, err)
+	}
+
+	got, err := c.Next(2)
+	if err != nil || len(got) != 2 {
+		t.Fatalf("Next(2) = (%v, %v)", got, err)
+	}
+	if n, _ := c.Len(); n != 1 {
+		t.Fatalf("Len() = %d after checkout, want 1", n)
+	}
+
+	if err := c.Nack(got...); err != nil {
+		t.Fatalf("Nack: %v", err)
+	}
+	if n, _ := c.Len(); n != 3 {
+		t.Fatalf("Len() = %d after Nack, want 3", n)
+	}
+
+	rest, err := c.Next(3)
+	if err != nil || len(rest) != 3 {
+		t.Fatalf("Next(3) = (%v, %v)", rest, err)
+	}
+	if err := c.Ack(rest...); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+
+	if err := c.Reset(); err != nil {
+		t.Fatalf("Reset: %v", err)
+	}
+	if n, _ := c.Len(); n != 3 {
+		t.Fatalf("Len() = %d after Reset, want 3", n)
+	}
 }
 
 func testServeForFile(t *testing.T) {
@@ -96,7 +155,7 @@ func testServeForFile(t *testing.T) {
 			true,
 		},
 	} {
-		s, err := NewServer(f, c.bindAddress)
+		s, err := NewLegacyServer(f, c.bindAddress)
 		if err != nil {
 			t.Fatalf("Unexpected error when creating server object: %v", err)
 		}
@@ -202,7 +261,8 @@ func TestIsHostPort(t *testing.T) {
 	}
 }
 
-/**
+/*
+*
 Helper functions
 */
 func createTestTCPListener(resp []byte) (*net.TCPListener, int) {